@@ -1,6 +1,7 @@
 package binding
 
 import (
+	"database/sql/driver"
 	"reflect"
 )
 
@@ -26,6 +27,18 @@ func NewBinding() *Binding {
 	}
 }
 
+// 复制当前的参数
+func (b *Binding) Clone() *Binding {
+	c := &Binding{
+		args:      make(map[string][]interface{}, len(b.args)),
+		keysOrder: append([]string{}, b.keysOrder...),
+	}
+	for k, v := range b.args {
+		c.args[k] = append([]interface{}{}, v...)
+	}
+	return c
+}
+
 func (b *Binding) AddBinding(typ string, val interface{}) {
 	if _, ok := b.args[typ]; ok {
 		b.args[typ] = append(b.args[typ], val)
@@ -41,9 +54,16 @@ func (b *Binding) GetBindings() (bindings []interface{}) {
 	return bindings
 }
 
+// update t join ... set ... where ... order by
+// join的参数位于set之前, where和order的参数位于set之后
 func (b *Binding) PrepareUpdateBinding(values []interface{}) (bindings []interface{}) {
+	for _, k := range []string{"from", "join"} {
+		for _, v := range b.args[k] {
+			mergeBindings(&bindings, v)
+		}
+	}
 	bindings = append(bindings, values...)
-	keys := []string{"from", "where", "having", "order", "union"}
+	keys := []string{"where", "having", "order", "union"}
 	for _, k := range keys {
 		for _, v := range b.args[k] {
 			mergeBindings(&bindings, v)
@@ -52,8 +72,9 @@ func (b *Binding) PrepareUpdateBinding(values []interface{}) (bindings []interfa
 	return
 }
 
+// delete t from t join ... where ... order by
 func (b *Binding) PrepareDeleteBinding() (bindings []interface{}) {
-	keys := []string{"where"}
+	keys := []string{"from", "join", "where", "order"}
 	for _, k := range keys {
 		for _, v := range b.args[k] {
 			mergeBindings(&bindings, v)
@@ -65,9 +86,9 @@ func (b *Binding) PrepareDeleteBinding() (bindings []interface{}) {
 func mergeBindings(bindings *[]interface{}, v interface{}) {
 	switch v.(type) {
 	case
-		int, int8, int32, int64,
-		uint, uint8, uint32, uint64,
-		float32, float64, string, bool:
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64, string, bool, driver.Valuer:
 		*bindings = append(*bindings, v)
 	case
 		[]int, []int8, []int16, []int32, []int64,
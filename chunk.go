@@ -0,0 +1,112 @@
+package sprydb
+
+import (
+	"reflect"
+
+	"github.com/Soul-Mate/sprydb/binding"
+	"github.com/Soul-Mate/sprydb/define"
+	"github.com/Soul-Mate/sprydb/query"
+)
+
+// 使用offset分页, 每次查询size条数据到dest并调用f
+// dest是struct slice的指针, 每一页都会清空后重新填充
+// f返回错误时停止查询, 并将错误返回给调用者
+func (s *Session) Chunk(size int, dest interface{}, f func(batch interface{}) error, column ...string) error {
+	return s.chunk(size, dest, f, func(page int, last reflect.Value) error {
+		s.queryBuilder.Skip(page * size)
+		return nil
+	}, column...)
+}
+
+// 使用column分页, 每次查询column大于上一页最后一条数据的size条数据
+// 迭代过程中修改数据不会导致遗漏, column需要是唯一且递增的列, 通常是主键
+// 查询会按column升序排列, 调用前不应该设置其他排序
+func (s *Session) ChunkByID(size int, column string, dest interface{}, f func(batch interface{}) error, selectColumn ...string) error {
+	s.queryBuilder.OrderBy(column, "asc")
+	return s.chunk(size, dest, f, func(page int, last reflect.Value) error {
+		if page == 0 {
+			return nil
+		}
+		item := reflect.New(last.Type())
+		item.Elem().Set(last)
+		itemMapper, err := s.parseModel(item.Interface())
+		if err != nil {
+			return err
+		}
+		value, ok := itemMapper.GetValueByColumn(column)
+		if !ok {
+			return define.ChunkColumnError
+		}
+		s.queryBuilder.Where(column, ">", value)
+		return nil
+	}, selectColumn...)
+}
+
+// 分页查询的公共流程, paginate在每一页查询之前设置分页条件
+// last是上一页的最后一条数据
+func (s *Session) chunk(size int, dest interface{}, f func(batch interface{}) error,
+	paginate func(page int, last reflect.Value) error, column ...string) error {
+	var (
+		err     error
+		builder *query.Builder
+		bind    *binding.Binding
+		last    reflect.Value
+		with    []string
+	)
+
+	defer s.resetBuilder()
+
+	if size <= 0 {
+		return define.ChunkSizeError
+	}
+
+	if dest == nil {
+		return define.ObjectNoneError
+	}
+
+	reflectType := reflect.TypeOf(dest)
+	if reflectType.Kind() != reflect.Ptr || reflectType.Elem().Kind() != reflect.Slice ||
+		reflectType.Elem().Elem().Kind() != reflect.Struct {
+		return define.UnsupportedTypeError
+	}
+	elem := reflect.ValueOf(dest).Elem()
+
+	// 保存调用前的查询状态, 每一页都从这份状态开始
+	bind = s.binding.Clone()
+	builder = s.queryBuilder.Clone(bind.Clone())
+	with = s.with
+
+	for page := 0; ; page++ {
+		s.restoreBuilder(builder, bind, with)
+		if err = paginate(page, last); err != nil {
+			return err
+		}
+		s.queryBuilder.Take(size)
+
+		elem.Set(reflect.MakeSlice(elem.Type(), 0, size))
+		if err = s.Get(dest, column...); err != nil {
+			return err
+		}
+
+		n := elem.Len()
+		if n <= 0 {
+			return nil
+		}
+		last = elem.Index(n - 1)
+		if err = f(dest); err != nil {
+			return err
+		}
+		if n < size {
+			return nil
+		}
+	}
+}
+
+// 使用保存的查询状态替换当前的查询状态, 保存的状态不会被修改
+// with是需要预加载的关联, Get结束时会被清空, 每一页都需要恢复
+func (s *Session) restoreBuilder(builder *query.Builder, bind *binding.Binding, with []string) {
+	s.binding = bind.Clone()
+	s.grammar = s.newGrammar()
+	s.queryBuilder = builder.Clone(s.binding)
+	s.with = append([]string(nil), with...)
+}
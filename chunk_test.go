@@ -0,0 +1,106 @@
+package sprydb
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestChunk(t *testing.T) {
+	columns, rows := fakeUserRows()
+	conn, d := newFakeConnection(t, columns, nil)
+	d.results = [][][]driver.Value{rows[:2], rows[2:]}
+
+	var (
+		users []fakeUser
+		names []string
+	)
+	err := conn.Table("users").Where("id", ">", 0).Chunk(2, &users, func(batch interface{}) error {
+		for _, u := range *batch.(*[]fakeUser) {
+			names = append(names, u.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 3 || names[2] != "baz" {
+		t.Errorf("TestChunk error: %v", names)
+	}
+	if len(d.queries) != 2 {
+		t.Fatalf("TestChunk error: %d queries", len(d.queries))
+	}
+	want := "select `id`,`name` from `users` where `id` > ? limit 2 offset 2"
+	if d.queries[1] != want {
+		t.Errorf("TestChunk error: %s", d.queries[1])
+	}
+	if len(d.args[1]) != 1 {
+		t.Errorf("TestChunk bindings error: %v", d.args[1])
+	}
+	assertNoConnectionInUse(t, conn, "Chunk")
+}
+
+func TestChunkByID(t *testing.T) {
+	columns, rows := fakeUserRows()
+	conn, d := newFakeConnection(t, columns, nil)
+	d.results = [][][]driver.Value{rows[:2], rows[2:]}
+
+	var users []fakeUser
+	stop := errors.New("stop")
+	pages := 0
+	err := conn.Table("users").WhereNotNull("name").ChunkByID(2, "id", &users, func(batch interface{}) error {
+		pages++
+		if pages == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("TestChunkByID should return the callback error, got %v", err)
+	}
+	want := "select `id`,`name` from `users` where `name` is not null and `id` > ? order by `id` asc limit 2"
+	if len(d.queries) != 2 || d.queries[1] != want {
+		t.Errorf("TestChunkByID error: %v", d.queries)
+	}
+	if len(d.args[1]) != 1 || d.args[1][0] != int64(2) {
+		t.Errorf("TestChunkByID bindings error: %v", d.args[1])
+	}
+	assertNoConnectionInUse(t, conn, "ChunkByID")
+}
+
+func TestChunkWith(t *testing.T) {
+	conn, d := newFakeConnection(t, nil, nil)
+	d.results = [][][]driver.Value{
+		{{int64(1), []byte("foo")}, {int64(2), []byte("bar")}},
+		{{int64(10), int64(1)}, {int64(11), int64(2)}},
+		{{int64(3), []byte("baz")}},
+		{{int64(12), int64(3)}},
+	}
+	d.columnsList = [][]string{{"id", "name"}, {"id", "user_id"}, {"id", "name"}, {"id", "user_id"}}
+
+	var (
+		users []relUser
+		posts []int64
+	)
+	err := NewSession(conn).With("Posts").Chunk(2, &users, func(batch interface{}) error {
+		for _, u := range *batch.(*[]relUser) {
+			if len(u.Posts) != 1 {
+				t.Errorf("TestChunkWith posts of user %d: %+v", u.Id, u.Posts)
+				continue
+			}
+			posts = append(posts, u.Posts[0].Id)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 3 || posts[2] != 12 {
+		t.Errorf("TestChunkWith error: %v", posts)
+	}
+	want := "select `id`,`user_id` from `posts` where `user_id` in (?)"
+	if len(d.queries) != 4 || d.queries[3] != want {
+		t.Errorf("TestChunkWith queries error: %v", d.queries)
+	}
+	assertNoConnectionInUse(t, conn, "ChunkWith")
+}
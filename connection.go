@@ -31,6 +31,20 @@ type Connection struct {
 	cache   *sync.Map
 	logging *logging.Logging
 	style   mapper.MapperStyler
+	// 没有查询到数据时First, Find不返回错误
+	lenientNotFound bool
+	// map查询结果不按照列类型转换, 所有[]byte转换为string
+	rawMapValues bool
+	// Named使用的查询本
+	queryBook *QueryBook
+	// 关联关系key列的字段下标, key是fieldIndexKey
+	fieldIndex sync.Map
+	// 自动时间字段的精度, 零值表示精确到秒
+	timestampPrecision time.Duration
+	// 自动时间字段的时区, 为空时使用time.Now的时区
+	timestampLocation *time.Location
+	// 映射时间字段使用的格式和时区
+	timeFormat mapper.TimeFormat
 }
 
 func NewConnection(config map[string]string) (*Connection, error) {
@@ -126,6 +140,43 @@ func (c *Connection) SetMapperStyle(style mapper.MapperStyler) {
 	c.style = style
 }
 
+// 设置为true时, First, Find等查询不到数据时不返回ErrRecordNotFound
+func (c *Connection) SetLenientNotFound(lenient bool) {
+	c.lenientNotFound = lenient
+}
+
+// 设置为true时, FirstReturnMap, GetReturnMap等查询的结果保持旧的行为,
+// 所有[]byte类型的值转换为string, 不按照列类型转换
+func (c *Connection) SetRawMapValues(raw bool) {
+	c.rawMapValues = raw
+}
+
+// 设置Named使用的查询本
+func (c *Connection) SetQueryBook(book *QueryBook) {
+	c.queryBook = book
+}
+
+// 设置自动时间字段和软删除时间的精度, 例如time.Millisecond
+func (c *Connection) SetTimestampPrecision(precision time.Duration) {
+	c.timestampPrecision = precision
+}
+
+// 设置自动时间字段和软删除时间的时区
+func (c *Connection) SetTimestampLocation(loc *time.Location) {
+	c.timestampLocation = loc
+}
+
+// 设置时间字段写入和解析的格式, 可以使用date, datetime(6), timestamp等列类型,
+// 字段的time_layout优先
+func (c *Connection) SetTimeLayout(layout string) {
+	c.timeFormat.Layout = layout
+}
+
+// 设置时间字段写入前转换到的时区以及解析使用的时区, 字段的tz优先
+func (c *Connection) SetTimeLocation(loc *time.Location) {
+	c.timeFormat.Location = loc
+}
+
 // 关闭数据库连接
 func (c *Connection) Close() error {
 	var err error
@@ -176,11 +227,21 @@ func (c *Connection) Table(tableName string) *Session {
 	return session
 }
 
-func (c *Connection) Find(id int, object interface{}, column ...string) (err error) {
+func (c *Connection) Find(id interface{}, object interface{}, column ...string) (err error) {
 	session := NewSession(c)
 	return session.Find(id, object, column...)
 }
 
+func (c *Connection) FindMany(ids interface{}, objects interface{}, column ...string) error {
+	session := NewSession(c)
+	return session.FindMany(ids, objects, column...)
+}
+
+func (c *Connection) FindManyInOrder(ids interface{}, objects interface{}, column ...string) error {
+	session := NewSession(c)
+	return session.FindManyInOrder(ids, objects, column...)
+}
+
 func (c *Connection) Select(column ...string) *Session {
 	session := NewSession(c)
 	session.Select(column...)
@@ -353,6 +414,36 @@ func (c *Connection) First(object interface{}, column ...string) error {
 	return nil
 }
 
+func (c *Connection) Cursor(object interface{}, column ...string) (*Cursor, error) {
+	session := NewSession(c)
+	return session.Cursor(object, column...)
+}
+
+func (c *Connection) Chunk(size int, dest interface{}, f func(batch interface{}) error, column ...string) error {
+	session := NewSession(c)
+	return session.Chunk(size, dest, f, column...)
+}
+
+func (c *Connection) ChunkByID(size int, column string, dest interface{}, f func(batch interface{}) error, selectColumn ...string) error {
+	session := NewSession(c)
+	return session.ChunkByID(size, column, dest, f, selectColumn...)
+}
+
+func (c *Connection) Raw(sqlStr string, args ...interface{}) *RawQuery {
+	session := NewSession(c)
+	return session.Raw(sqlStr, args...)
+}
+
+func (c *Connection) Named(name string, args ...interface{}) *RawQuery {
+	session := NewSession(c)
+	return session.Named(name, args...)
+}
+
+func (c *Connection) Model(object interface{}) *Session {
+	session := NewSession(c)
+	return session.Model(object)
+}
+
 func (c *Connection) Insert(value interface{}) (lastInsertId, rowsAffected int64, err error) {
 	session := NewSession(c)
 	return session.Insert(value)
@@ -363,6 +454,16 @@ func (c *Connection) Update(value interface{}) (rowsAffected int64, err error) {
 	return session.Update(value)
 }
 
+func (c *Connection) Save(object interface{}) error {
+	session := NewSession(c)
+	return session.Save(object)
+}
+
+func (c *Connection) DeleteModel(object interface{}) (rowsAffected int64, err error) {
+	session := NewSession(c)
+	return session.DeleteModel(object)
+}
+
 func (c *Connection) EnableQueryLog() *logging.Logging {
 	once.Do(func() {
 		if c.logging == nil {
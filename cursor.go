@@ -0,0 +1,184 @@
+package sprydb
+
+import (
+	"database/sql"
+	"reflect"
+
+	"github.com/Soul-Mate/sprydb/define"
+	"github.com/Soul-Mate/sprydb/mapper"
+)
+
+// 逐行读取结果集的游标
+// 所有行共用一个映射器和扫描地址, 内存占用与结果集大小无关
+type Cursor struct {
+	err        error
+	rows       *sql.Rows
+	raw        bool
+	timeFormat mapper.TimeFormat
+	columns    []*sql.ColumnType
+	address    []interface{}
+	object     reflect.Value // 映射器绑定的对象, 每次Scan都会覆盖
+	objMapper  *mapper.Mapper
+	child      *Session // 调用AfterFind钩子使用的session
+}
+
+// 创建struct游标, object是struct指针, 只用于确定映射的类型
+func (s *Session) Cursor(object interface{}, column ...string) (*Cursor, error) {
+	var (
+		err       error
+		stmt      *sql.Stmt
+		rows      *sql.Rows
+		sqlStr    string
+		address   []interface{}
+		objMapper *mapper.Mapper
+	)
+
+	defer s.resetBuilder()
+
+	if err = s.queryBuilder.GetErr(); err != nil {
+		return nil, err
+	}
+
+	if object == nil {
+		return nil, define.ObjectNoneError
+	}
+
+	t := reflect.TypeOf(object)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, define.UnsupportedTypeError
+	}
+
+	// 使用新的对象进行映射, 避免修改调用者传入的对象
+	proto := reflect.New(t.Elem())
+	if objMapper, address, err = s.selectMapper(proto.Interface(), column...); err != nil {
+		return nil, err
+	}
+
+	if sqlStr, err = s.grammar.CompileSelect(s.queryBuilder); err != nil {
+		return nil, err
+	}
+
+	if s.connection.logging != nil {
+		defer s.connection.logging.Append(sqlStr, s.binding.GetBindings()...)
+	}
+
+	if stmt, err = s.prepare(sqlStr); err != nil {
+		return nil, err
+	}
+
+	if rows, err = s.query(stmt, s.binding.GetBindings()...); err != nil {
+		return nil, err
+	}
+
+	return &Cursor{
+		rows:      rows,
+		address:   address,
+		object:    proto,
+		objMapper: objMapper,
+		child:     s.childSession(),
+	}, nil
+}
+
+// 创建map游标, 每一行扫描为map[string]interface{}
+func (s *Session) CursorReturnMap() (*Cursor, error) {
+	var (
+		err     error
+		stmt    *sql.Stmt
+		rows    *sql.Rows
+		sqlStr  string
+		columns []*sql.ColumnType
+	)
+
+	defer s.resetBuilder()
+
+	if err = s.queryBuilder.GetErr(); err != nil {
+		return nil, err
+	}
+
+	if sqlStr, err = s.grammar.CompileSelect(s.queryBuilder); err != nil {
+		return nil, err
+	}
+
+	if s.connection.logging != nil {
+		defer s.connection.logging.Append(sqlStr, s.binding.GetBindings()...)
+	}
+
+	if stmt, err = s.prepare(sqlStr); err != nil {
+		return nil, err
+	}
+
+	if rows, err = s.query(stmt, s.binding.GetBindings()...); err != nil {
+		return nil, err
+	}
+
+	if columns, err = rows.ColumnTypes(); err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	return &Cursor{
+		rows:       rows,
+		raw:        s.connection.rawMapValues,
+		timeFormat: s.connection.timeFormat,
+		columns:    columns,
+	}, nil
+}
+
+// 移动到下一行, 没有数据或者发生错误时返回false并关闭游标
+func (c *Cursor) Next() bool {
+	if c.err != nil {
+		return false
+	}
+	if c.rows.Next() {
+		return true
+	}
+	c.err = c.rows.Err()
+	c.rows.Close()
+	return false
+}
+
+// 扫描当前行
+// struct游标的dest是与创建时相同类型的struct指针,
+// map游标的dest是*map[string]interface{}
+func (c *Cursor) Scan(dest interface{}) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	if c.objMapper == nil {
+		m, ok := dest.(*map[string]interface{})
+		if !ok {
+			return define.UnsupportedTypeError
+		}
+		result, err := scanMap(c.rows, c.columns, c.raw, c.timeFormat)
+		if err != nil {
+			return err
+		}
+		*m = result
+		return nil
+	}
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.Elem().Type() != c.object.Elem().Type() {
+		return define.UnsupportedTypeError
+	}
+
+	if err := c.rows.Scan(c.address...); err != nil {
+		return err
+	}
+	if err := c.objMapper.AssignAddressValue(); err != nil {
+		return err
+	}
+	v.Elem().Set(c.object.Elem())
+	return callHookValue(v, mapper.AfterFind, c.child)
+}
+
+// 迭代过程中发生的错误
+func (c *Cursor) Err() error {
+	return c.err
+}
+
+// 关闭游标, 提前结束迭代时必须调用
+func (c *Cursor) Close() error {
+	return c.rows.Close()
+}
@@ -0,0 +1,66 @@
+package sprydb
+
+import (
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	columns, rows := fakeUserRows()
+	conn, _ := newFakeConnection(t, columns, rows)
+
+	cursor, err := conn.Cursor(&fakeUser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for cursor.Next() {
+		user := fakeUser{}
+		if err = cursor.Scan(&user); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, user.Name)
+	}
+	if err = cursor.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 3 || names[0] != "foo" || names[2] != "baz" {
+		t.Errorf("TestCursor error: %v", names)
+	}
+	assertNoConnectionInUse(t, conn, "Cursor")
+
+	// 提前结束迭代
+	if cursor, err = conn.Cursor(&fakeUser{}); err != nil {
+		t.Fatal(err)
+	}
+	cursor.Next()
+	if err = cursor.Scan(&map[string]interface{}{}); err == nil {
+		t.Error("TestCursor error: struct cursor scanned into map")
+	}
+	if err = cursor.Close(); err != nil {
+		t.Fatal(err)
+	}
+	assertNoConnectionInUse(t, conn, "Cursor")
+}
+
+func TestCursorReturnMap(t *testing.T) {
+	columns, rows := fakeUserRows()
+	conn, d := newFakeConnection(t, columns, rows)
+	d.iterErrAt = 3
+
+	cursor, err := conn.Table("users").CursorReturnMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for cursor.Next() {
+		row := map[string]interface{}{}
+		if err = cursor.Scan(&row); err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != 2 || cursor.Err() != errFakeIter {
+		t.Errorf("TestCursorReturnMap error: %d rows, %v", n, cursor.Err())
+	}
+	assertNoConnectionInUse(t, conn, "CursorReturnMap")
+}
@@ -1,7 +1,9 @@
 package define
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 )
 
 var (
@@ -20,6 +22,33 @@ var (
 	NullPointerAndNotAssign        = errors.New("this field is a null pointer and cannot be assigned")
 	FieldSliceTypeError            = errors.New("the slice type field only support uint8")
 	TransactionAlreadyUseErr       = errors.New("the transaction already use, please commit or rollabck.")
+	MultiTableOrderLimitError      = errors.New("update or delete with join cannot use order by and limit")
+	UpdateDeleteOffsetError        = errors.New("update or delete cannot use offset")
+	PrimaryKeyNoneError            = errors.New("the object has no primary key field")
+	PrimaryKeyZeroError            = errors.New("the primary key of the object is zero value")
+	PrimaryKeyTypeError            = errors.New("only integer primary keys can be assigned the generated id")
+	ChunkSizeError                 = errors.New("the chunk size must be greater than zero")
+	ChunkColumnError               = errors.New("the chunk column is not mapped by the object")
+	PerPageError                   = errors.New("the per page must be greater than zero")
+	CursorOrderNoneError           = errors.New("cursor pagination requires order by columns")
+	CursorOrderDirectionError      = errors.New("cursor pagination requires all order by columns to use the same direction")
+	CursorColumnError              = errors.New("the cursor column is not mapped by the object or is null")
+	InvalidCursorError             = errors.New("invalid pagination cursor")
+	KeyColumnError                 = errors.New("the key column is not mapped by the object or is null")
+	NamedParameterError            = errors.New("missing named parameter")
+	NamedQueryNotFoundError        = errors.New("the named query does not exist")
+	DuplicateNamedQueryError       = errors.New("the named query is defined more than once")
+	EmptyNamedQueryError           = errors.New("the named query is empty")
+	FilterColumnError              = errors.New("the filter column is not allowed")
+	FilterValueError               = errors.New("invalid filter value")
+	RelationNotFoundError          = errors.New("the relation is not declared")
+	RelationKeyError               = errors.New("the relation key column is not mapped by the object")
+	SoftDeleteNoneError            = errors.New("the model has no soft delete column")
+	NotTrackedError                = errors.New("the object is not tracked by the session")
+	KeyTypeError                   = errors.New("the key column value cannot convert to the map key type")
+	HookPointerError               = errors.New("the object has hooks with pointer receivers, pass a pointer to call them")
+	ScannerTypeError               = errors.New("the field type implements driver.Valuer but not sql.Scanner and cannot be read")
+	TimeValueError                 = errors.New("the time field value is not a time.Time or mapper.Time")
 )
 
 var (
@@ -28,3 +57,9 @@ var (
 	UnsupportedTypeError = errors.New("Unsupported type")
 	InvalidOperatorError = errors.New("Invalid where operator.")
 )
+
+// 查询不到数据时返回, 可以使用errors.Is与sql.ErrNoRows比较
+var ErrRecordNotFound = fmt.Errorf("record not found: %w", sql.ErrNoRows)
+
+// 使用乐观锁更新时没有影响任何行, 数据已经被其它操作修改或删除
+var ErrStaleObject = errors.New("the object has been modified or deleted by another operation")
@@ -0,0 +1,72 @@
+package sprydb
+
+import (
+	"reflect"
+
+	"github.com/Soul-Mate/sprydb/define"
+	"github.com/Soul-Mate/sprydb/mapper"
+)
+
+// 模型的生命周期钩子, 对象实现了对应的方法时由映射器检测并调用,
+// 钩子返回error时中止操作. 钩子的参数是与当前操作使用同一个事务的新session,
+// 可以在钩子中执行其它查询
+type BeforeInsertHook interface {
+	BeforeInsert(s *Session) error
+}
+
+type AfterInsertHook interface {
+	AfterInsert(s *Session) error
+}
+
+type BeforeUpdateHook interface {
+	BeforeUpdate(s *Session) error
+}
+
+type AfterUpdateHook interface {
+	AfterUpdate(s *Session) error
+}
+
+type BeforeDeleteHook interface {
+	BeforeDelete(s *Session) error
+}
+
+type AfterFindHook interface {
+	AfterFind(s *Session) error
+}
+
+// 调用对象的钩子, object可以是struct指针, struct的slice或slice指针,
+// slice中的每个对象都会调用, 遇到错误时停止.
+// 钩子定义在指针上而object是struct值时无法调用, 返回HookPointerError
+func (s *Session) callHook(object interface{}, name string) error {
+	if object == nil {
+		return nil
+	}
+	return callHookValue(reflect.ValueOf(object), name, s.childSession())
+}
+
+func callHookValue(v reflect.Value, name string, child *Session) error {
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Slice {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Struct && !v.MethodByName(name).IsValid() {
+			if _, ok := reflect.PtrTo(v.Type()).MethodByName(name); ok {
+				return define.HookPointerError
+			}
+		}
+		return mapper.CallHookMethod(v, name, child)
+	}
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+		if item.Kind() == reflect.Struct {
+			item = item.Addr()
+		}
+		if err := callHookValue(item, name, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package sprydb
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/Soul-Mate/sprydb/define"
+)
+
+var errHookInvalid = errors.New("invalid name")
+
+type hookUser struct {
+	Id    int64    `spry:"col:id"`
+	Name  string   `spry:"col:name"`
+	calls []string `spry:"-"`
+	seen  int64    `spry:"-"` // AfterInsert中读取到的主键
+}
+
+func (hookUser) Table() string { return "users" }
+
+func (u *hookUser) BeforeInsert(s *Session) error {
+	u.calls = append(u.calls, "BeforeInsert")
+	if u.Name == "" {
+		return errHookInvalid
+	}
+	return nil
+}
+
+func (u *hookUser) AfterInsert(s *Session) error {
+	u.calls = append(u.calls, "AfterInsert")
+	u.seen = u.Id
+	return nil
+}
+
+func (u *hookUser) BeforeUpdate(s *Session) error {
+	u.calls = append(u.calls, "BeforeUpdate")
+	return nil
+}
+
+func (u *hookUser) AfterUpdate(s *Session) error {
+	u.calls = append(u.calls, "AfterUpdate")
+	return nil
+}
+
+func (u *hookUser) BeforeDelete(s *Session) error {
+	u.calls = append(u.calls, "BeforeDelete")
+	return nil
+}
+
+func (u *hookUser) AfterFind(s *Session) error {
+	u.Name = "found " + u.Name
+	return nil
+}
+
+func TestSession_Hooks(t *testing.T) {
+	conn, d := newFakeConnection(t, []string{"id", "name"}, [][]driver.Value{{int64(1), "foo"}, {int64(2), "bar"}})
+
+	// BeforeInsert返回错误时中止插入
+	invalid := &hookUser{}
+	if _, _, err := conn.Insert(invalid); err != errHookInvalid {
+		t.Errorf("want hook error, got %v", err)
+	}
+	if len(d.execs) != 0 {
+		t.Errorf("insert should be aborted: %v", d.execs)
+	}
+
+	user := &hookUser{Name: "foo"}
+	if _, _, err := conn.Insert(user); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Where("id", "=", 1).Update(user); err != nil {
+		t.Fatal(err)
+	}
+	user.Id = 1
+	if _, err := conn.DeleteModel(user); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"BeforeInsert", "AfterInsert", "BeforeUpdate", "AfterUpdate", "BeforeDelete"}
+	if len(user.calls) != len(want) {
+		t.Fatalf("hook calls error: %v", user.calls)
+	}
+	for i := range want {
+		if user.calls[i] != want[i] {
+			t.Errorf("hook calls error: %v", user.calls)
+		}
+	}
+
+	var users []*hookUser
+	if err := conn.Table("users").Get(&users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0].Name != "found foo" || users[1].Name != "found bar" {
+		t.Errorf("AfterFind not called on Get: %v", users)
+	}
+	first := &hookUser{}
+	if err := conn.First(first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Name != "found foo" {
+		t.Errorf("AfterFind not called on First: %s", first.Name)
+	}
+}
+
+func TestSession_HookPointerRequired(t *testing.T) {
+	conn, d := newFakeConnection(t, nil, nil)
+	if _, _, err := conn.Insert(hookUser{Name: "foo"}); err != define.HookPointerError {
+		t.Errorf("want HookPointerError, got %v", err)
+	}
+	if len(d.execs) != 0 {
+		t.Errorf("insert should be aborted: %v", d.execs)
+	}
+	// 没有钩子的struct值不受影响
+	if _, _, err := conn.Insert(fakeUser{Name: "foo"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSession_AfterFindHook(t *testing.T) {
+	rows := [][]driver.Value{{int64(1), "foo"}, {int64(2), "bar"}}
+	conn, _ := newFakeConnection(t, []string{"id", "name"}, rows)
+
+	cursor, err := conn.Table("users").Cursor(&hookUser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for cursor.Next() {
+		var u hookUser
+		if err = cursor.Scan(&u); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, u.Name)
+	}
+	if len(names) != 2 || names[0] != "found foo" || names[1] != "found bar" {
+		t.Errorf("AfterFind not called on Cursor: %v", names)
+	}
+
+	keyed := map[int64]hookUser{}
+	if err = conn.Table("users").GetKeyed("id", &keyed); err != nil {
+		t.Fatal(err)
+	}
+	if keyed[1].Name != "found foo" || keyed[2].Name != "found bar" {
+		t.Errorf("AfterFind not called on GetKeyed: %v", keyed)
+	}
+
+	var one hookUser
+	if err = conn.Raw("select id, name from users").Get(&one); err != nil {
+		t.Fatal(err)
+	}
+	if one.Name != "found foo" {
+		t.Errorf("AfterFind not called on Raw struct: %s", one.Name)
+	}
+	var many []*hookUser
+	if err = conn.Raw("select id, name from users").Scan(&many); err != nil {
+		t.Fatal(err)
+	}
+	if len(many) != 2 || many[0].Name != "found foo" || many[1].Name != "found bar" {
+		t.Errorf("AfterFind not called on Raw slice: %v", many)
+	}
+	assertNoConnectionInUse(t, conn, "AfterFind")
+}
+
+func TestSession_AfterInsertSeesID(t *testing.T) {
+	conn, _ := newFakeConnection(t, nil, nil)
+	user := &hookUser{Name: "foo"}
+	if err := conn.Save(user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Id != 1 || user.seen != 1 {
+		t.Errorf("AfterInsert should see the generated id: id %d, seen %d", user.Id, user.seen)
+	}
+}
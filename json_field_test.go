@@ -0,0 +1,58 @@
+package sprydb
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+type jsonProfile struct {
+	Theme string `json:"theme"`
+}
+
+type jsonUser struct {
+	Id       int64             `spry:"col:id"`
+	Settings map[string]string `spry:"col:settings;json"`
+	Tags     []string          `spry:"col:tags;json:empty"`
+	Profile  *jsonProfile      `spry:"col:profile;json"`
+}
+
+func (jsonUser) Table() string { return "users" }
+
+func TestSession_JSONField(t *testing.T) {
+	conn, d := newFakeConnection(t, []string{"id", "settings", "tags", "profile"}, [][]driver.Value{
+		{int64(1), []byte(`{"lang":"en"}`), []byte(`["a","b"]`), nil},
+		{int64(2), []byte(`{"tz":"UTC"}`), nil, []byte(`{"theme":"dark"}`)},
+	})
+
+	var users []jsonUser
+	if err := conn.Table("users").Get(&users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("Get error: %v", users)
+	}
+	// 每一行的map不会合并上一行的数据
+	if len(users[0].Settings) != 1 || users[0].Settings["lang"] != "en" || len(users[1].Settings) != 1 {
+		t.Errorf("settings error: %v %v", users[0].Settings, users[1].Settings)
+	}
+	if len(users[0].Tags) != 2 || users[1].Tags != nil {
+		t.Errorf("tags error: %v %v", users[0].Tags, users[1].Tags)
+	}
+	if users[0].Profile != nil || users[1].Profile == nil || users[1].Profile.Theme != "dark" {
+		t.Errorf("profile error: %v %v", users[0].Profile, users[1].Profile)
+	}
+
+	if _, _, err := conn.Insert(&jsonUser{Settings: map[string]string{"lang": "zh"}}); err != nil {
+		t.Fatal(err)
+	}
+	args := d.args[len(d.args)-1]
+	if len(args) != 4 || args[1] != `{"lang":"zh"}` || args[2] != "[]" || args[3] != nil {
+		t.Errorf("insert bindings error: %v", args)
+	}
+
+	// 无效的json返回错误
+	d.rows = [][]driver.Value{{int64(3), []byte(`{`), nil, nil}}
+	if err := conn.Table("users").Get(&users); err == nil {
+		t.Error("want unmarshal error")
+	}
+}
@@ -0,0 +1,64 @@
+package sprydb
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Soul-Mate/sprydb/mapper"
+)
+
+// 数据库返回的日期时间格式, 小数秒的位数不固定
+var columnTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// 根据列的数据库类型转换[]byte类型的值, 日期时间使用连接的格式和时区解析
+// 无法识别的类型转换为string
+func convertColumnValue(typeName string, b []byte, format mapper.TimeFormat) (interface{}, error) {
+	typeName = strings.ToUpper(typeName)
+	switch strings.TrimPrefix(typeName, "UNSIGNED ") {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "YEAR":
+		if strings.HasPrefix(typeName, "UNSIGNED ") {
+			return strconv.ParseUint(string(b), 10, 64)
+		}
+		return strconv.ParseInt(string(b), 10, 64)
+	case "DECIMAL", "FLOAT", "DOUBLE":
+		return strconv.ParseFloat(string(b), 64)
+	case "DATE", "DATETIME", "TIMESTAMP":
+		return parseColumnTime(string(b), format)
+	case "JSON":
+		return json.RawMessage(append([]byte{}, b...)), nil
+	case "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY", "BIT", "GEOMETRY":
+		return append([]byte{}, b...), nil
+	}
+	return string(b), nil
+}
+
+// 解析日期时间, 与struct字段相同, 优先使用连接设置的格式, 时区为空时使用UTC
+// 零值日期返回time.Time的零值
+func parseColumnTime(s string, format mapper.TimeFormat) (time.Time, error) {
+	if strings.HasPrefix(s, "0000-00-00") {
+		return time.Time{}, nil
+	}
+	var (
+		t   time.Time
+		err error
+	)
+	loc := time.UTC
+	if format.Location != nil {
+		loc = format.Location
+	}
+	layouts := columnTimeLayouts
+	if format.Layout != "" {
+		layouts = append([]string{mapper.ResolveTimeLayout(format.Layout)}, layouts...)
+	}
+	for _, layout := range layouts {
+		if t, err = time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return t, err
+}
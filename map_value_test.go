@@ -0,0 +1,91 @@
+package sprydb
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSession_TypedMapValues(t *testing.T) {
+	conn, d := newFakeConnection(t,
+		[]string{"id", "hits", "price", "created_at", "meta", "avatar", "name", "score"},
+		[][]driver.Value{{
+			[]byte("1"), []byte("18446744073709551615"), []byte("9.99"), []byte("2018-01-02 03:04:05.123"),
+			[]byte(`{"a":1}`), []byte{0xff}, []byte("foo"), int64(7),
+		}})
+	d.types = []string{"BIGINT", "UNSIGNED BIGINT", "DECIMAL", "DATETIME", "JSON", "BLOB", "VARCHAR", "INT"}
+
+	result, err := conn.Table("items").FirstReturnMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"id":         int64(1),
+		"hits":       uint64(18446744073709551615),
+		"price":      9.99,
+		"created_at": time.Date(2018, 1, 2, 3, 4, 5, 123000000, time.UTC),
+		"name":       "foo",
+		"score":      int64(7),
+	}
+	for k, v := range want {
+		if result[k] != v {
+			t.Errorf("column %s: got %#v, want %#v", k, result[k], v)
+		}
+	}
+	if meta, ok := result["meta"].(json.RawMessage); !ok || string(meta) != `{"a":1}` {
+		t.Errorf("column meta: got %#v", result["meta"])
+	}
+	if avatar, ok := result["avatar"].([]byte); !ok || avatar[0] != 0xff {
+		t.Errorf("column avatar: got %#v", result["avatar"])
+	}
+
+	conn.SetRawMapValues(true)
+	results, err := conn.Table("items").GetReturnMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0]["id"] != "1" || results[0]["price"] != "9.99" || results[0]["score"] != int64(7) {
+		t.Errorf("raw map values error: %v", results[0])
+	}
+}
+
+func TestSession_MapValuesTimeFormat(t *testing.T) {
+	type event struct {
+		Id        int64     `spry:"col:id"`
+		CreatedAt time.Time `spry:"col:created_at"`
+	}
+	loc := time.FixedZone("UTC+8", 8*3600)
+	conn, d := newFakeConnection(t, []string{"id", "created_at"},
+		[][]driver.Value{{[]byte("1"), []byte("2018-01-02 03:04:05")}})
+	d.types = []string{"BIGINT", "DATETIME"}
+	conn.SetTimeLocation(loc)
+
+	result, err := conn.Table("events").FirstReturnMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2018, 1, 2, 3, 4, 5, 0, loc)
+	if got, ok := result["created_at"].(time.Time); !ok || !got.Equal(want) || got.Location() != loc {
+		t.Errorf("map time location error: %#v", result["created_at"])
+	}
+	var e event
+	if err = conn.Table("events").First(&e); err != nil {
+		t.Fatal(err)
+	}
+	if !e.CreatedAt.Equal(result["created_at"].(time.Time)) {
+		t.Errorf("map time %v differs from struct time %v", result["created_at"], e.CreatedAt)
+	}
+
+	// 连接设置的格式优先于默认格式
+	conn2, d2 := newFakeConnection(t, []string{"created_at"}, [][]driver.Value{{[]byte("02/01/2018")}})
+	d2.types = []string{"DATE"}
+	conn2.SetTimeLayout("02/01/2006")
+	results, err := conn2.Table("events").GetReturnMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := results[0]["created_at"]; got != time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("map time layout error: %#v", got)
+	}
+}
@@ -25,3 +25,34 @@ func CallPKMethod(v reflect.Value) string {
 	}
 	return ret.String()
 }
+
+// 模型生命周期钩子的方法名
+const (
+	BeforeInsert = "BeforeInsert"
+	AfterInsert  = "AfterInsert"
+	BeforeUpdate = "BeforeUpdate"
+	AfterUpdate  = "AfterUpdate"
+	BeforeDelete = "BeforeDelete"
+	AfterFind    = "AfterFind"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// 调用对象的钩子方法, 方法的签名必须是func(arg) error
+// 方法不存在或签名不匹配时不调用, 返回nil
+func CallHookMethod(v reflect.Value, name string, arg interface{}) error {
+	method := v.MethodByName(name)
+	if !method.IsValid() {
+		return nil
+	}
+	mt := method.Type()
+	argValue := reflect.ValueOf(arg)
+	if mt.NumIn() != 1 || mt.NumOut() != 1 || mt.Out(0) != errorType ||
+		!argValue.Type().AssignableTo(mt.In(0)) {
+		return nil
+	}
+	if ret := method.Call([]reflect.Value{argValue})[0]; !ret.IsNil() {
+		return ret.Interface().(error)
+	}
+	return nil
+}
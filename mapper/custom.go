@@ -1,6 +1,29 @@
 package mapper
 
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+)
+
 type Custom interface {
 	ReadFromDB([]byte) // 从数据库中读出的数据
 	WriteToDB() []byte // 往数据库写入数据
-}
\ No newline at end of file
+}
+
+var (
+	customType  = reflect.TypeOf((*Custom)(nil)).Elem()
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// 字段类型实现了sql.Scanner或driver.Valuer, 例如sql.NullString, uuid.UUID
+// 实现了Custom的类型和time.Time仍然使用原有的处理方式
+// 只实现了driver.Valuer的类型不能用于查询, 解析时返回ScannerTypeError
+func isScannerValuer(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	if t == timeType || pt.Implements(customType) {
+		return false
+	}
+	return pt.Implements(scannerType) || pt.Implements(valuerType)
+}
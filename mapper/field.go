@@ -3,6 +3,7 @@ package mapper
 import (
 	"reflect"
 	"database/sql"
+	"database/sql/driver"
 	"time"
 )
 
@@ -23,6 +24,14 @@ type Field struct {
 	nullString  sql.NullString
 	nullBool    sql.NullBool
 	extend      *ExtendField
+	// 指针字段, nullPtr是struct中的指针字段, nullValue是select时扫描使用的临时值
+	nullable  bool
+	nullPtr   reflect.Value
+	nullValue reflect.Value
+	src       interface{} // 指针Scanner字段扫描的原始值
+	// 时间字段的格式和时区
+	timeLayout   string
+	timeLocation *time.Location
 }
 
 func newNullTypeField(tag *Tag) *Field {
@@ -35,7 +44,57 @@ func newNullTypeField(tag *Tag) *Field {
 	}
 }
 
-func (f *Field) assignValue() {
+// 指针字段的列是NULL时设置为nil, 否则为每一行分配新的值,
+// 避免多行共用一个对象时指向同一个地址
+func (f *Field) assignValue() error {
+	if !f.nullable {
+		return f.assignAddrValue()
+	}
+	if f.isNull() {
+		f.nullPtr.Set(reflect.Zero(f.nullPtr.Type()))
+		return nil
+	}
+	value := reflect.New(f.nullPtr.Type().Elem())
+	if f.typ == "scanner" {
+		if err := value.Interface().(sql.Scanner).Scan(f.src); err != nil {
+			return err
+		}
+		f.nullPtr.Set(value)
+		return nil
+	}
+	if err := f.assignAddrValue(); err != nil {
+		return err
+	}
+	value.Elem().Set(f.nullValue.Elem())
+	f.nullPtr.Set(value)
+	return nil
+}
+
+// 列的值是否是NULL, 只用于指针字段
+func (f *Field) isNull() bool {
+	switch f.typ {
+	case
+		"int", "uint",
+		"int8", "uint8",
+		"int16", "uint16",
+		"int32", "uint32",
+		"int64", "uint64":
+		return !f.nullInt64.Valid
+	case "float32", "float64":
+		return !f.nullFloat64.Valid
+	case "string":
+		return !f.nullString.Valid
+	case "bool":
+		return !f.nullBool.Valid
+	case "custom", "time", "raw":
+		return f.raw == nil
+	case "scanner":
+		return f.src == nil
+	}
+	return false
+}
+
+func (f *Field) assignAddrValue() error {
 	switch f.typ {
 	case "int":
 		*f.addr.(*int) = int(f.nullInt64.Int64)
@@ -66,23 +125,17 @@ func (f *Field) assignValue() {
 	case "bool":
 		*f.addr.(*bool) = f.nullBool.Bool
 	case "time":
-		// TODO 引入配置
-		var layout = "2006-01-02 15:04:05"
-		switch (*f).addr.(type) {
-		case time.Time:
-			t, _ := time.Parse(layout, string(f.raw))
-			(*f).addr = t
-		case *time.Time:
-			t, _ := time.Parse(layout, string(f.raw))
-			*f.addr.(*time.Time) = t
-		}
+		return f.parseTime()
 	case "custom":
 		(*f).addr.(Custom).ReadFromDB((*f).raw)
+	case "json":
+		return f.unmarshalJSON()
 	case "raw":
 		*f.addr.(*[]byte) = make([]byte, len(f.raw))
 		*f.addr.(*[]byte) = f.raw
 	default:
 	}
+	return nil
 }
 
 // get sql.Null<T> type pointer
@@ -102,7 +155,16 @@ func (f *Field) getFieldAddr() interface{} {
 		return &f.nullString
 	case "bool":
 		return &f.nullBool
-	case "custom", "time":
+	case "custom", "time", "json":
+		return &f.raw
+	case "scanner":
+		if f.nullable {
+			return &f.src
+		}
+		// 由database/sql直接扫描到字段中
+		if scanner, ok := f.addr.(sql.Scanner); ok {
+			return scanner
+		}
 		return &f.raw
 	case "raw":
 		return &f.raw
@@ -113,62 +175,106 @@ func (f *Field) getFieldAddr() interface{} {
 
 // 获取字段插入操作的值
 // 默认零值是写入的
-func (f *Field) getInsertValue() interface{} {
+func (f *Field) getInsertValue() (interface{}, error) {
 	switch f.typ {
 	case "time":
 		if f.isZero() {
-			return nil
-		}
-		var layout = "2006-01-02 15:04:05"
-		if tm, ok := (*f).addr.(time.Time); ok {
-			return tm.Format(layout)
+			return nil, nil
 		}
-		if tm, ok := (*f).addr.(*time.Time); ok {
-			return tm.Format(layout)
-		}
-		return nil
+		return f.formatTime()
 	case "custom":
-		return (*f).addr.(Custom).WriteToDB()
+		return (*f).addr.(Custom).WriteToDB(), nil
+	case "scanner":
+		return f.getValuerValue(), nil
+	case "json":
+		return f.getJSONValue(), nil
 	case "null":
-		return nil
+		return nil, nil
 	default:
-		return (*f).addr
+		return (*f).addr, nil
 	}
 }
 
-// 获取字段更新操作的值
-// 会处理零值是否写入的情况
-func (f *Field) getUpdateValue() interface{} {
+// 实现了driver.Valuer的字段交给database/sql调用Value(),
+// 只实现了sql.Scanner的字段使用字段本身的值
+func (f *Field) getValuerValue() interface{} {
+	if valuer, ok := f.addr.(driver.Valuer); ok {
+		return valuer
+	}
+	if f.fv.Kind() == reflect.Ptr {
+		return f.fv.Elem().Interface()
+	}
+	return f.fv.Interface()
+}
+
+// 获取字段更新操作的值, ok为false时跳过该字段
+// 会处理零值是否写入的情况, 空指针字段写入NULL
+func (f *Field) getUpdateValue() (value interface{}, ok bool, err error) {
 	switch f.typ {
 	case "time":
-		var layout = "2006-01-02 15:04:05"
 		// go语言中字段会默认使用空值,
 		// 如果字段是空值但设置了不更新空值则跳过该字段的更新
-		if f.isZero() && !f.tag.updateZero {
-			return nil
+		if f.skipZero(f.isZero()) {
+			return nil, false, nil
 		}
-		switch (*f).addr.(type) {
-		case time.Time:
-			return (*f).addr.(time.Time).Format(layout)
-		case *time.Time:
-			return (*f).addr.(*time.Time).Format(layout)
+		if value, err = f.formatTime(); err != nil {
+			return nil, false, err
 		}
-		return nil
+		return value, true, nil
 	case "custom":
 		// 调用自定义字段的Write
-		if data := (*f).addr.(Custom).WriteToDB(); len(data) <= 0 && !f.tag.updateZero {
-			return nil
-		} else {
-			return data
+		data := (*f).addr.(Custom).WriteToDB()
+		if f.skipZero(len(data) <= 0) {
+			return nil, false, nil
+		}
+		return data, true, nil
+	case "scanner":
+		if f.skipZero(f.isZero()) {
+			return nil, false, nil
 		}
+		return f.getValuerValue(), true, nil
+	case "json":
+		if f.skipZero(f.isZero()) {
+			return nil, false, nil
+		}
+		return f.getJSONValue(), true, nil
 	case "null":
-		return nil
+		return nil, true, nil
 	default:
-		if !f.tag.updateZero && f.isZero() {
-			return nil
+		if f.skipZero(f.isZero()) {
+			return nil, false, nil
 		}
-		return (*f).addr
+		return (*f).addr, true, nil
+	}
+}
+
+// 比较修改使用的值, 与插入时写入的值相同, 但不引用对象中的数据
+func (f *Field) getCompareValue() (interface{}, error) {
+	value, err := f.getInsertValue()
+	if err != nil {
+		return nil, err
 	}
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		value = v
+	} else if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		value = rv.Elem().Interface()
+	}
+	if b, ok := value.([]byte); ok {
+		value = append([]byte(nil), b...)
+	}
+	return value, nil
+}
+
+// 更新时是否跳过零值, 声明了update_zero或者是非空指针字段时不跳过
+func (f *Field) skipZero(zero bool) bool {
+	return zero && !f.tag.updateZero && !f.nullable
 }
 
 // 判断字段的值是否是零值
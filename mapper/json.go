@@ -0,0 +1,50 @@
+package mapper
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// 序列化json字段, nil的map, slice和指针返回nil, 写入NULL
+// 声明了json:empty时, nil的slice写入[], 其它写入{}
+func (f *Field) marshalJSON() ([]byte, error) {
+	switch f.fv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Ptr, reflect.Interface:
+		if f.fv.IsNil() {
+			if !f.tag.jsonEmpty {
+				return nil, nil
+			}
+			if f.fv.Kind() == reflect.Slice {
+				return []byte("[]"), nil
+			}
+			return []byte("{}"), nil
+		}
+	}
+	b, err := json.Marshal(f.fv.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("marshal json column %s: %w", f.tagString, err)
+	}
+	return b, nil
+}
+
+// 反序列化json字段, NULL和空字符串将字段设置为零值
+// 先将字段设置为零值, 避免多行共用一个对象时map合并了上一行的数据
+func (f *Field) unmarshalJSON() error {
+	f.fv.Set(reflect.Zero(f.fv.Type()))
+	if len(f.raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(f.raw, f.addr); err != nil {
+		return fmt.Errorf("unmarshal json column %s: %w", f.tagString, err)
+	}
+	return nil
+}
+
+// json字段写入的值
+func (f *Field) getJSONValue() interface{} {
+	if f.raw == nil {
+		return nil
+	}
+	return string(f.raw)
+}
@@ -4,7 +4,9 @@ import (
 	"reflect"
 	"github.com/Soul-Mate/sprydb/define"
 	"errors"
+	"fmt"
 	"regexp"
+	"strings"
 	"github.com/Soul-Mate/sprydb/syntax"
 	"time"
 	)
@@ -16,17 +18,21 @@ const (
 )
 
 type Mapper struct {
-	fm        *fieldMap
-	tm        *tableMapper
-	parseType int
-	pk        string
-	ot        reflect.Type  // object reflect.Type
-	ov        reflect.Value // object reflect.Value
-	opv       reflect.Value // 当object是ptr的时候, 这份保存指针
-	ptr       bool
-	fields    []*Field
-	syntax    syntax.Syntax
-	style     MapperStyler
+	fm         *fieldMap
+	tm         *tableMapper
+	parseType  int
+	pk         string
+	softDelete string        // 软删除的列
+	version    string        // 乐观锁的版本号列
+	timeFormat *TimeFormat   // 连接设置的时间格式
+	onlyUpdate map[string]bool
+	ot         reflect.Type  // object reflect.Type
+	ov         reflect.Value // object reflect.Value
+	opv        reflect.Value // 当object是ptr的时候, 这份保存指针
+	ptr        bool
+	fields     []*Field
+	syntax     syntax.Syntax
+	style      MapperStyler
 }
 
 func NewMapper(object interface{}, syntax syntax.Syntax, style MapperStyler) (*Mapper, error) {
@@ -78,6 +84,113 @@ func (m *Mapper) GetPK() string {
 	return m.pk
 }
 
+// 获取主键字段的值, 需要在Parse之后调用
+// 如果主键字段不存在或是零值, ok为false
+func (m *Mapper) GetPKValue() (value interface{}, ok bool, err error) {
+	f, exist := m.fm.get(m.GetPK())
+	if !exist {
+		return nil, false, define.PrimaryKeyNoneError
+	}
+	// 空指针主键
+	if f.fv == nil {
+		return nil, false, nil
+	}
+	if f.isZero() {
+		return f.fv.Interface(), false, nil
+	}
+	return f.fv.Interface(), true, nil
+}
+
+// 回写数据库生成的主键, 只支持整数类型的主键
+// 其它类型的主键(如string, UUID)返回PrimaryKeyTypeError
+// 主键是空指针时分配新的值再赋值
+func (m *Mapper) SetPKValue(id int64) error {
+	f, exist := m.fm.get(m.GetPK())
+	if !exist {
+		return define.PrimaryKeyNoneError
+	}
+	if f.fv != nil {
+		if !f.fv.CanSet() {
+			return define.NullPointerAndNotAssign
+		}
+		return setIntValue(*f.fv, id)
+	}
+
+	// 空指针主键被解析为NULL字段, 通过字段下标找到指针
+	index, ok := m.GetFieldIndex(m.GetPK())
+	if !ok || !m.ptr {
+		return define.NullPointerAndNotAssign
+	}
+	ptr, err := m.opv.FieldByIndexErr(index)
+	if err != nil || ptr.Kind() != reflect.Ptr || !ptr.CanSet() {
+		return define.NullPointerAndNotAssign
+	}
+	value := reflect.New(ptr.Type().Elem())
+	if err = setIntValue(value.Elem(), id); err != nil {
+		return err
+	}
+	ptr.Set(value)
+	return nil
+}
+
+func setIntValue(v reflect.Value, id int64) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(uint64(id))
+	default:
+		return define.PrimaryKeyTypeError
+	}
+	return nil
+}
+
+// 获取映射到column列的字段在struct中的下标, 需要在Parse之后调用
+// 用于在同一类型的多个对象中重复读取同一列, 不需要为每个对象解析映射器
+func (m *Mapper) GetFieldIndex(column string) ([]int, bool) {
+	f, ok := m.getFieldByColumn(column)
+	if !ok || f.tag == nil {
+		return nil, false
+	}
+	sf, ok := m.ot.FieldByName(f.tag.fieldName)
+	if !ok {
+		return nil, false
+	}
+	return sf.Index, true
+}
+
+// 列是否映射到了字段, 需要在Parse之后调用
+func (m *Mapper) HasColumn(column string) bool {
+	_, ok := m.getFieldByColumn(column)
+	return ok
+}
+
+// 获取列对应字段的值, 需要在Parse之后调用
+// 列可以带有表名或别名前缀
+func (m *Mapper) GetValueByColumn(column string) (interface{}, bool) {
+	f, ok := m.getFieldByColumn(column)
+	if !ok || f.fv == nil {
+		return nil, false
+	}
+	if f.nullable {
+		if f.nullPtr.IsNil() {
+			return nil, false
+		}
+		return f.nullPtr.Elem().Interface(), true
+	}
+	return f.fv.Interface(), true
+}
+
+func (m *Mapper) getFieldByColumn(column string) (*Field, bool) {
+	f, ok := m.fm.get(column)
+	if !ok {
+		if i := strings.LastIndex(column, "."); i >= 0 {
+			f, ok = m.fm.get(column[i+1:])
+		}
+	}
+	return f, ok
+}
+
 func (m *Mapper) GetTable() string {
 	return m.tm.table
 }
@@ -180,10 +293,59 @@ func (m *Mapper) Parse(parseType int) (err error) {
 	m.tm.Parse(m.ov, m.ot, m.syntax, m.style) // 初始化table映射
 
 	//m.parseTableMapper(m.ov)
-	m.fields, err = m.parseFields(m.ot, fv, m.tm.alias)
+	if m.fields, err = m.parseFields(m.ot, fv, m.tm.alias); err != nil {
+		return
+	}
+	for _, f := range m.fields {
+		if f.tag != nil && f.tag.softDelete {
+			m.softDelete = f.tagString
+		}
+		if f.tag != nil && f.tag.version && f.fv != nil && isIntegerKind(f.fv.Kind()) {
+			m.version = f.tagString
+		}
+	}
 	return
 }
 
+// 乐观锁的版本号列, 没有声明version或字段不是整数时返回空
+func (m *Mapper) GetVersionColumn() string {
+	return m.version
+}
+
+// 版本号字段加一, 更新成功后调用
+func (m *Mapper) IncrVersion() {
+	f, ok := m.fm.get(m.version)
+	if !ok || !f.fv.CanSet() {
+		return
+	}
+	switch f.fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f.fv.SetInt(f.fv.Int() + 1)
+	default:
+		f.fv.SetUint(f.fv.Uint() + 1)
+	}
+}
+
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// 设置连接的时间格式, 需要在Parse之前调用
+func (m *Mapper) SetTimeFormat(format *TimeFormat) {
+	m.timeFormat = format
+}
+
+// 软删除的列, 没有声明soft_delete时返回空
+func (m *Mapper) GetSoftDeleteColumn() string {
+	return m.softDelete
+}
+
 // 解析struct中的字段
 func (m *Mapper) parseFields(t reflect.Type, v reflect.Value, alias string) (fields []*Field, err error) {
 	var (
@@ -210,6 +372,16 @@ func (m *Mapper) parseFields(t reflect.Type, v reflect.Value, alias string) (fie
 
 // 处理字段
 func (m *Mapper) parseField(ff reflect.StructField, fv reflect.Value, tag *Tag, alias string) (*Field, error) {
+	if tag.json {
+		return m.createJSONTypeField(fv, tag, alias)
+	}
+	if fv.Kind() != reflect.Ptr && isScannerValuer(fv.Type()) {
+		addr, err := m.decideColumnAddr(false, fv)
+		if err != nil {
+			return nil, err
+		}
+		return m.createScannerTypeField(fv, addr, tag, alias)
+	}
 	switch fv.Type().Kind() {
 	case reflect.Ptr:
 		return m.parsePtrField(ff, fv, tag, alias)
@@ -229,38 +401,109 @@ func (m *Mapper) parseField(ff reflect.StructField, fv reflect.Value, tag *Tag,
 
 // 处理指针字段类型
 func (m *Mapper) parsePtrField(ff reflect.StructField, pfv reflect.Value, tag *Tag, alias string) (*Field, error) {
+	extend := IsExtendStruct(ff.Type.Elem())
 	if pfv.IsNil() {
-		// 解析类型是select, 对这个空指针进行赋值
-		if m.parseType == PARSE_SELECT {
+		switch {
+		case extend && m.parseType == PARSE_SELECT:
+			// 连接查询的struct, 对这个空指针进行赋值
 			if !pfv.CanSet() {
 				return nil, define.NullPointerAndNotAssign
 			}
-			// 对空指针设置内存
 			pfv.Set(reflect.New(ff.Type.Elem()))
-		}
-
-		// 类型为空类型, 返回空值
-		if m.parseType == PARSE_INSERT || m.parseType == PARSE_UPDATE {
+		case extend:
+			// 插入和更新时忽略空的连接查询struct
+			return &Field{tag: tag, extend: &ExtendField{alias: alias, fields: &[]*Field{}}}, nil
+		case m.parseType == PARSE_INSERT || m.parseType == PARSE_UPDATE:
+			// 空指针写入NULL
 			field := newNullTypeField(tag)
 			m.fm.add(tag.column, field)
 			return field, nil
 		}
 	}
 
+	if !extend && m.parseType == PARSE_SELECT {
+		return m.parseNullableField(ff, pfv, tag, alias)
+	}
+
+	var (
+		err   error
+		field *Field
+	)
 	elem := pfv.Elem()
-	switch elem.Kind() {
+	switch {
+	case isScannerValuer(ff.Type.Elem()):
+		var addr interface{}
+		if addr, err = m.decideColumnAddr(true, pfv); err == nil {
+			field, err = m.createScannerTypeField(pfv, addr, tag, alias)
+		}
+	case elem.Kind() == reflect.Slice:
+		field, err = m.parseSliceFieldType(ff, elem, tag, alias)
+	case elem.Kind() == reflect.Struct:
+		field, err = m.parseStructTypeField(ff, pfv, tag, true, alias)
+	case isUnsupportedKind(elem.Kind()):
+		err = define.UnsupportedTypeError
+	default:
+		field, err = m.parseBasicTypeField(ff, elem, tag, m.tm.alias)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !extend {
+		field.nullable = true
+		field.nullPtr = pfv
+	}
+	return field, nil
+}
+
+// select时不为指针字段分配内存, 扫描到临时的值中,
+// 赋值时根据列是否是NULL设置指针
+func (m *Mapper) parseNullableField(ff reflect.StructField, pfv reflect.Value, tag *Tag, alias string) (*Field, error) {
+	var (
+		err   error
+		field *Field
+	)
+	if !pfv.CanSet() {
+		return nil, define.NullPointerAndNotAssign
+	}
+	elemType := ff.Type.Elem()
+	value := reflect.New(elemType)
+	switch {
+	case isScannerValuer(elemType):
+		field, err = m.createScannerTypeField(value, value.Interface(), tag, alias)
+	case elemType.Kind() == reflect.Slice:
+		field, err = m.parseSliceFieldType(ff, value.Elem(), tag, alias)
+	case elemType.Kind() == reflect.Struct:
+		field, err = m.parseStructTypeField(ff, value, tag, true, alias)
+	case isUnsupportedKind(elemType.Kind()):
+		err = define.UnsupportedTypeError
+	default:
+		field, err = m.parseBasicTypeField(ff, value.Elem(), tag, alias)
+	}
+	if err != nil {
+		return nil, err
+	}
+	field.nullable = true
+	field.nullPtr = pfv
+	field.nullValue = value
+	return field, nil
+}
+
+// 指向普通struct的指针字段是连接查询的struct, 不是可以为NULL的值
+// time.Time, Time, Custom, sql.Scanner和driver.Valuer作为一个值处理, 不是连接查询的struct
+func IsExtendStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && t != mapperTimeType &&
+		!reflect.PtrTo(t).Implements(customType) && !isScannerValuer(t)
+}
+
+func isUnsupportedKind(kind reflect.Kind) bool {
+	switch kind {
 	case
 		reflect.Map, reflect.Array,
 		reflect.Chan, reflect.Complex64,
 		reflect.Func, reflect.Complex128:
-		return nil, define.UnsupportedTypeError
-	case reflect.Slice:
-		return m.parseSliceFieldType(ff, elem, tag, alias)
-	case reflect.Struct:
-		return m.parseStructTypeField(ff, pfv, tag, true, alias)
-	default:
-		return m.parseBasicTypeField(ff, elem, tag, m.tm.alias)
+		return true
 	}
+	return false
 }
 
 // 处理类型是struct的字段以及特殊字段
@@ -274,8 +517,8 @@ func (m *Mapper) parseStructTypeField(ff reflect.StructField, fv reflect.Value,
 
 	// 特殊类型字段进行特殊处理
 	switch addr.(type) {
-	case time.Time, *time.Time: // time字段
-		return m.createTimeTypeField(fv, addr, tag, alias), nil
+	case time.Time, *time.Time, Time, *Time: // time字段
+		return m.createTimeTypeField(fv, addr, tag, alias)
 	case Custom: // 用户自定义字段
 		return m.createCustomTypeField(fv, addr, tag, alias), nil
 	default:
@@ -442,8 +685,53 @@ func (m *Mapper) createCustomTypeField(fv reflect.Value, addr interface{}, tag *
 	return field
 }
 
+// 实现了sql.Scanner或driver.Valuer的类型, addr是字段的指针
+func (m *Mapper) createScannerTypeField(fv reflect.Value, addr interface{}, tag *Tag, alias string) (*Field, error) {
+	column := m.decideColumnName(tag, alias)
+	// 只实现了driver.Valuer的类型可以写入, 但是无法读取
+	t := fv.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if m.parseType == PARSE_SELECT && !reflect.PtrTo(t).Implements(scannerType) {
+		return nil, fmt.Errorf("column %s: %w", column, define.ScannerTypeError)
+	}
+	field := &Field{
+		tag:       tag,
+		addr:      addr,
+		typ:       "scanner",
+		tagString: column,
+		fv:        &fv,
+	}
+	m.fm.add(column, field)
+	return field, nil
+}
+
+// json序列化的字段, 插入和更新时在解析阶段序列化, 以便返回序列化的错误
+func (m *Mapper) createJSONTypeField(fv reflect.Value, tag *Tag, alias string) (*Field, error) {
+	addr, err := m.decideColumnAddr(false, fv)
+	if err != nil {
+		return nil, err
+	}
+	column := m.decideColumnName(tag, alias)
+	field := &Field{
+		tag:       tag,
+		addr:      addr,
+		typ:       "json",
+		tagString: column,
+		fv:        &fv,
+	}
+	if m.parseType == PARSE_INSERT || m.parseType == PARSE_UPDATE {
+		if field.raw, err = field.marshalJSON(); err != nil {
+			return nil, err
+		}
+	}
+	m.fm.add(column, field)
+	return field, nil
+}
+
 // time类型
-func (m *Mapper) createTimeTypeField(fv reflect.Value, addr interface{}, tag *Tag, alias string) *Field {
+func (m *Mapper) createTimeTypeField(fv reflect.Value, addr interface{}, tag *Tag, alias string) (*Field, error) {
 	column := m.decideColumnName(tag, alias)
 	field := &Field{
 		tag:       tag,
@@ -452,8 +740,11 @@ func (m *Mapper) createTimeTypeField(fv reflect.Value, addr interface{}, tag *Ta
 		tagString: column,
 		fv:        &fv,
 	}
+	if err := m.decideTimeFormat(field); err != nil {
+		return nil, err
+	}
 	m.fm.add(column, field)
-	return field
+	return field, nil
 }
 
 // 决定扩展字段的别名
@@ -510,7 +801,7 @@ func (m *Mapper) parseBasicTypeField(ff reflect.StructField, fv reflect.Value, t
 
 	field := &Field{
 		tag:       tag,
-		typ:       ff.Type.String(),
+		typ:       fv.Type().String(),
 		tagString: column,
 		addr:      addr,
 		fv:        &fv,
@@ -580,27 +871,80 @@ func (m *Mapper) decideColumnAddr(ptr bool, fv reflect.Value) (interface{}, erro
 	}
 }
 
+// 获取每一列写入数据库的值, 用于比较对象的修改, 需要在Parse(PARSE_UPDATE)之后调用
+// 返回的值不引用对象中的数据
+func (m *Mapper) GetColumnValues() (columns []string, values []interface{}, err error) {
+	for _, c := range m.fm.k {
+		f, ok := m.fm.get(c)
+		if !ok {
+			continue
+		}
+		value, err := f.getCompareValue()
+		if err != nil {
+			return nil, nil, err
+		}
+		columns = append(columns, c)
+		values = append(values, value)
+	}
+	return
+}
+
 // 为映射对象中的字段地址赋值
-func (m *Mapper) AssignAddressValue() {
+func (m *Mapper) AssignAddressValue() error {
 	for _, f := range m.fm.m {
-		f.assignValue()
+		if err := f.assignValue(); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func (m *Mapper) GetInsertColumnAndValues() (columns []string, values []interface{}) {
+func (m *Mapper) GetInsertColumnAndValues() (columns []string, values []interface{}, err error) {
 	for _, c := range m.fm.k {
 		if f, ok := m.fm.get(c); ok {
-			values = append(values, f.getInsertValue())
+			value, err := f.getInsertValue()
+			if err != nil {
+				return nil, nil, err
+			}
+			values = append(values, value)
 			columns = append(columns, c)
 		}
 	}
 	return
 }
 
-func (m *Mapper) GetUpdateColumnAndValues() (columns []string, values []interface{}) {
+// 更新时只更新指定的列, 包括零值, 自动更新时间的列总是更新
+func (m *Mapper) SetUpdateColumns(columns []string) {
+	if len(columns) <= 0 {
+		m.onlyUpdate = nil
+		return
+	}
+	m.onlyUpdate = make(map[string]bool, len(columns))
+	for _, c := range columns {
+		m.onlyUpdate[c] = true
+	}
+}
+
+func (m *Mapper) GetUpdateColumnAndValues() (columns []string, values []interface{}, err error) {
 	for _, c := range m.fm.k {
-		if f, ok := m.fm.get(c); ok {
-			if value := f.getUpdateValue(); value != nil {
+		f, ok := m.fm.get(c)
+		if ok && m.onlyUpdate != nil {
+			if m.onlyUpdate[c] || f.tag.isAutoUpdateTime() {
+				value, err := f.getInsertValue()
+				if err != nil {
+					return nil, nil, err
+				}
+				values = append(values, value)
+				columns = append(columns, c)
+			}
+			continue
+		}
+		if ok {
+			value, ok, err := f.getUpdateValue()
+			if err != nil {
+				return nil, nil, err
+			}
+			if ok {
 				values = append(values, value)
 				columns = append(columns, c)
 			}
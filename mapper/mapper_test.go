@@ -3,6 +3,8 @@ package mapper
 import (
 	"testing"
 	"errors"
+	"reflect"
+	"github.com/Soul-Mate/sprydb/define"
 	"github.com/Soul-Mate/sprydb/syntax"
 	)
 
@@ -65,7 +67,7 @@ func parseFieldDefineColumn() error {
 	if objMapper, err = NewMapper(&obj, syntax2, nil); err != nil {
 		return err
 	}
-	if err = objMapper.Parse(); err != nil {
+	if err = objMapper.Parse(PARSE_SELECT); err != nil {
 		return err
 	}
 	columns, address := objMapper.GetColumnAndAddress()
@@ -111,7 +113,7 @@ func parseFieldIgnoreField() error {
 		return err
 	}
 
-	if err = objMapper.Parse(); err != nil {
+	if err = objMapper.Parse(PARSE_SELECT); err != nil {
 		return err
 	}
 	columns, address := objMapper.GetColumnAndAddress()
@@ -152,7 +154,7 @@ func parseFiledPointerField() error {
 	if objMapper, err = NewMapper(&obj, syntax2, nil); err != nil {
 		return err
 	}
-	if err = objMapper.Parse(); err != nil {
+	if err = objMapper.Parse(PARSE_SELECT); err != nil {
 		return err
 	}
 	columns, address := objMapper.GetColumnAndAddress()
@@ -195,7 +197,7 @@ func parseFieldSubField() error {
 	}
 	objMapper.SetAlias("")
 	objMapper.SetJoinMap(nil)
-	if err = objMapper.Parse(); err != nil {
+	if err = objMapper.Parse(PARSE_SELECT); err != nil {
 		return err
 	}
 	columns, address := objMapper.GetColumnAndAddress()
@@ -244,7 +246,7 @@ func parseFieldExternalField() error {
 	if objMapper, err = NewMapper(&obj, syntax2, nil); err != nil {
 		return err
 	}
-	if err = objMapper.Parse(); err != nil {
+	if err = objMapper.Parse(PARSE_SELECT); err != nil {
 		return err
 	}
 	columns, address := objMapper.GetColumnAndAddress()
@@ -297,7 +299,7 @@ func parseCustomField() error {
 	}
 	objMapper.SetAlias("")
 	objMapper.SetJoinMap(nil)
-	if err = objMapper.Parse(); err != nil {
+	if err = objMapper.Parse(PARSE_SELECT); err != nil {
 		return err
 	}
 	columns, address := objMapper.GetColumnAndAddress()
@@ -398,7 +400,7 @@ func TestMapper_GetColumn(t *testing.T) {
 		t.Error(err)
 	}
 	objMapper.SetTable("foo")
-	if err = objMapper.Parse(); err != nil {
+	if err = objMapper.Parse(PARSE_SELECT); err != nil {
 		t.Error(err)
 	}
 	rawColumn := []string{
@@ -418,3 +420,53 @@ func TestMapper_GetColumn(t *testing.T) {
 	}
 }
 
+
+func TestMapper_PKValue(t *testing.T) {
+	obj := struct {
+		Id   int64  `spry:"col:id"`
+		Name string `spry:"col:name"`
+	}{}
+	mapper, err := NewMapper(&obj, syntax2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = mapper.Parse(PARSE_UPDATE); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := mapper.GetPKValue(); err != nil || ok {
+		t.Error("TestMapper_PKValue error: zero pk")
+	}
+	if err = mapper.SetPKValue(10); err != nil {
+		t.Fatal(err)
+	}
+	if obj.Id != 10 {
+		t.Error("TestMapper_PKValue error: pk not assigned")
+	}
+	if v, ok, err := mapper.GetPKValue(); err != nil || !ok || v != int64(10) {
+		t.Error("TestMapper_PKValue error")
+	}
+
+	none := struct {
+		Name string `spry:"col:name"`
+	}{}
+	if mapper, err = NewMapper(&none, syntax2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = mapper.Parse(PARSE_UPDATE); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = mapper.GetPKValue(); err != define.PrimaryKeyNoneError {
+		t.Error("TestMapper_PKValue error: missing pk")
+	}
+}
+
+func TestField_TimeValueError(t *testing.T) {
+	fv := reflect.ValueOf("2018-01-02")
+	f := &Field{tag: &Tag{}, typ: "time", addr: "2018-01-02", tagString: "created_at", fv: &fv}
+	if _, err := f.getInsertValue(); !errors.Is(err, define.TimeValueError) {
+		t.Errorf("TestField_TimeValueError insert: %v", err)
+	}
+	if _, _, err := f.getUpdateValue(); !errors.Is(err, define.TimeValueError) {
+		t.Errorf("TestField_TimeValueError update: %v", err)
+	}
+}
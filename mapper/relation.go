@@ -0,0 +1,124 @@
+package mapper
+
+import (
+	"reflect"
+	"strings"
+)
+
+// 关联关系的类型
+const (
+	HasOne     = "has_one"
+	HasMany    = "has_many"
+	BelongsTo  = "belongs_to"
+	ManyToMany = "many_to_many"
+)
+
+const (
+	foreignKeyTag     = "foreign_key"
+	localKeyTag       = "local_key"
+	ownerKeyTag       = "owner_key"
+	associationKeyTag = "association_key"
+)
+
+// 字段声明的关联关系
+//
+// has_one, has_many: 关联表的ForeignKey列保存当前对象LocalKey列的值
+// belongs_to: 当前对象的ForeignKey列保存关联对象OwnerKey列的值
+// many_to_many: 中间表JoinTable的ForeignKey列保存当前对象LocalKey列的值,
+// AssociationKey列保存关联对象OwnerKey列的值
+type Relation struct {
+	Kind           string
+	Field          string
+	Table          string // 关联对象的表, 为空时使用关联对象映射的表
+	JoinTable      string
+	ForeignKey     string
+	LocalKey       string
+	OwnerKey       string
+	AssociationKey string
+	Type           reflect.Type // 关联对象的struct类型
+	Slice          bool         // 字段是否是slice
+	Ptr            bool         // 字段或者slice的元素是否是指针
+}
+
+// 解析字段的关联关系, 字段没有声明关联关系时返回false
+// 没有声明的键使用默认值: 外键为类型名加Id, 本地键和所属键为主键
+func ParseRelation(owner reflect.Type, f reflect.StructField, style MapperStyler) (*Relation, bool) {
+	tag := f.Tag.Get(spryTag)
+	if tag == "" {
+		return nil, false
+	}
+	if style == nil {
+		style = &UnderlineMapperStyle{}
+	}
+
+	r := &Relation{Field: f.Name}
+	for _, group := range strings.Split(tag, ";") {
+		kv := strings.SplitN(group, ":", 2)
+		key := strings.TrimSpace(kv[0])
+		val := ""
+		if len(kv) > 1 {
+			val = strings.TrimSpace(kv[1])
+		}
+		switch key {
+		case HasOne, HasMany, BelongsTo:
+			r.Kind, r.Table = key, val
+		case ManyToMany:
+			r.Kind, r.JoinTable = key, val
+		case foreignKeyTag:
+			r.ForeignKey = val
+		case localKeyTag:
+			r.LocalKey = val
+		case ownerKeyTag:
+			r.OwnerKey = val
+		case associationKeyTag:
+			r.AssociationKey = val
+		}
+	}
+	if r.Kind == "" {
+		return nil, false
+	}
+
+	t := f.Type
+	if t.Kind() == reflect.Slice {
+		r.Slice = true
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		r.Ptr = true
+		t = t.Elem()
+	}
+	r.Type = t
+
+	ownerPK := CallPKMethod(reflect.New(owner).Elem())
+	relatedPK := CallPKMethod(reflect.New(r.Type).Elem())
+	switch r.Kind {
+	case HasOne, HasMany:
+		r.ForeignKey = defaultKey(r.ForeignKey, style.column(owner.Name()+"Id"))
+		r.LocalKey = defaultKey(r.LocalKey, ownerPK)
+	case BelongsTo:
+		r.ForeignKey = defaultKey(r.ForeignKey, style.column(f.Name+"Id"))
+		r.OwnerKey = defaultKey(r.OwnerKey, relatedPK)
+	case ManyToMany:
+		r.ForeignKey = defaultKey(r.ForeignKey, style.column(owner.Name()+"Id"))
+		r.AssociationKey = defaultKey(r.AssociationKey, style.column(r.Type.Name()+"Id"))
+		r.LocalKey = defaultKey(r.LocalKey, ownerPK)
+		r.OwnerKey = defaultKey(r.OwnerKey, relatedPK)
+	}
+	return r, true
+}
+
+func defaultKey(key, def string) string {
+	if key == "" {
+		return def
+	}
+	return key
+}
+
+// tag中是否声明了关联关系
+func isRelationTag(key string) bool {
+	switch key {
+	case HasOne, HasMany, BelongsTo, ManyToMany:
+		return true
+	}
+	return false
+}
@@ -14,6 +14,13 @@ const (
 	ignoreTag       = "ignore"
 	useAliasTag     = "use_alias"
 	updateZeroTag   = "update_zero"
+	softDeleteTag   = "soft_delete"
+	autoCreateTag   = "auto_create_time"
+	autoUpdateTag   = "auto_update_time"
+	versionTag      = "version"
+	jsonTag         = "json"
+	timeLayoutTag   = "time_layout"
+	timeZoneTag     = "tz"
 	ignoreSymbolTag = "-"
 )
 
@@ -23,16 +30,29 @@ type Tag struct {
 	column      string
 	useAlias    bool
 	updateZero  bool
+	softDelete  bool
+	autoCreate  bool
+	autoUpdate  bool
+	version     bool
+	json        bool
+	jsonEmpty   bool // nil值写入空的json, 而不是NULL
+	timeLayout  string
+	timeZone    string
 	extendTable string
 	extendAlias string
 	fv          *reflect.Value
 	f           *reflect.StructField
+	// 字段名和类型, f在解析struct时会被复用, 解析之后使用这两个值
+	fieldName string
+	fieldType reflect.Type
 }
 
 func newTag(field *reflect.StructField, fieldValue *reflect.Value) *Tag {
 	tag := new(Tag)
 	tag.f = field
 	tag.fv = fieldValue
+	tag.fieldName = field.Name
+	tag.fieldType = field.Type
 	tag.useAlias = true
 	return tag
 }
@@ -55,7 +75,8 @@ func (t *Tag) parse(fieldParser func(string) string, st syntax.Syntax) *Tag {
 	tagGroup := strings.Split(tag, ";")
 	for _, group := range tagGroup {
 		// 解析每个属性的值
-		tagAttributeGroup := strings.Split(group, ":")
+		// 只按照第一个:分割, 属性值中可以包含:, 例如time_layout
+		tagAttributeGroup := strings.SplitN(group, ":", 2)
 		switch len(tagAttributeGroup) {
 		case 0: // 没有属性
 			continue
@@ -67,6 +88,11 @@ func (t *Tag) parse(fieldParser func(string) string, st syntax.Syntax) *Tag {
 		// 判断属性分类
 		tagAttributeKey = strings.TrimSpace(tagAttributeGroup[0])
 		tagAttributeVal = strings.TrimSpace(tagAttributeVal)
+		// 关联关系字段不映射为列, 由session的With加载
+		if isRelationTag(tagAttributeKey) {
+			t.ignore = true
+			return t
+		}
 		switch tagAttributeKey {
 		case columnTag: // col
 			t.column = tagAttributeVal
@@ -78,6 +104,21 @@ func (t *Tag) parse(fieldParser func(string) string, st syntax.Syntax) *Tag {
 			if v, err := strconv.ParseBool(tagAttributeVal); err == nil {
 				t.updateZero = v
 			}
+		case softDeleteTag: // 软删除的列
+			t.softDelete = true
+		case autoCreateTag: // 插入时自动写入创建时间
+			t.autoCreate = true
+		case autoUpdateTag: // 插入和更新时自动写入更新时间
+			t.autoUpdate = true
+		case versionTag: // 乐观锁的版本号列
+			t.version = true
+		case jsonTag: // 序列化为json的字段, json:empty时nil值写入{}或[]
+			t.json = true
+			t.jsonEmpty = tagAttributeVal == "empty"
+		case timeLayoutTag: // 时间字段的格式, 可以是date, datetime(6)等列类型
+			t.timeLayout = tagAttributeVal
+		case timeZoneTag: // 时间字段的时区, 例如tz:Asia/Shanghai
+			t.timeZone = tagAttributeVal
 		case extendTag:
 			t.extend = true
 			t.extendTable, t.extendAlias = st.ParseTable(tagAttributeVal)
@@ -23,6 +23,6 @@ func TestNewTag(t *testing.T) {
 		pff := objType.Field(i)
 		pfv := objValue.Field(i)
 		tag := newTag(&pff, &pfv)
-		tag.parse(style.column)
+		tag.parse(style.column, syntax2)
 	}
 }
\ No newline at end of file
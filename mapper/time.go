@@ -1,7 +1,63 @@
 package mapper
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/Soul-Mate/sprydb/define"
+)
+
+// 常用的时间列格式
+const (
+	DateLayout      = "2006-01-02"
+	DatetimeLayout  = "2006-01-02 15:04:05"
+	Datetime3Layout = "2006-01-02 15:04:05.000"
+	Datetime6Layout = "2006-01-02 15:04:05.000000"
+)
+
+// 可以使用列类型代替格式, 例如time_layout:datetime(6)
+var namedTimeLayouts = map[string]string{
+	"date":         DateLayout,
+	"datetime":     DatetimeLayout,
+	"datetime(3)":  Datetime3Layout,
+	"datetime(6)":  Datetime6Layout,
+	"timestamp":    DatetimeLayout,
+	"timestamp(3)": Datetime3Layout,
+	"timestamp(6)": Datetime6Layout,
+}
+
+// 读取时按照字段的格式解析失败后依次尝试的格式,
+// 驱动直接返回time.Time时database/sql使用RFC3339Nano转换
+var fallbackTimeLayouts = []string{time.RFC3339Nano, DatetimeLayout, DateLayout}
+
+// 时间字段的格式, 在连接上设置, 字段的time_layout和tz优先
+type TimeFormat struct {
+	Layout   string         // 写入和解析的格式, 为空时使用DatetimeLayout
+	Location *time.Location // 写入前转换到的时区以及解析使用的时区, 为空时写入不转换, 解析使用UTC
+}
+
+// 按照格式和时区格式化t, 用于没有对象字段时写入时间, 例如软删除
+func (f TimeFormat) Format(t time.Time) string {
+	layout := DatetimeLayout
+	if f.Layout != "" {
+		layout = ResolveTimeLayout(f.Layout)
+	}
+	if f.Location != nil {
+		t = t.In(f.Location)
+	}
+	return t.Format(layout)
+}
+
+// 将列类型名称转换为格式, 其它值原样返回
+func ResolveTimeLayout(layout string) string {
+	if v, ok := namedTimeLayouts[strings.ToLower(layout)]; ok {
+		return v
+	}
+	return layout
+}
+
+// 带有格式的时间, 写入时优先使用自身的格式
 type Time struct {
 	time.Time
 	layout string
@@ -10,6 +66,108 @@ type Time struct {
 func NewTime(t time.Time, layout string) *Time {
 	return &Time{
 		t,
-		layout,
+		ResolveTimeLayout(layout),
+	}
+}
+
+// 决定字段的时间格式和时区, tag中声明的优先, 其次是连接的设置
+func (m *Mapper) decideTimeFormat(f *Field) error {
+	f.timeLayout = DatetimeLayout
+	if m.timeFormat != nil {
+		if m.timeFormat.Layout != "" {
+			f.timeLayout = ResolveTimeLayout(m.timeFormat.Layout)
+		}
+		f.timeLocation = m.timeFormat.Location
+	}
+	if f.tag.timeLayout != "" {
+		f.timeLayout = ResolveTimeLayout(f.tag.timeLayout)
 	}
-}
\ No newline at end of file
+	if f.tag.timeZone != "" {
+		loc, err := time.LoadLocation(f.tag.timeZone)
+		if err != nil {
+			return fmt.Errorf("time zone of column %s: %w", f.tagString, err)
+		}
+		f.timeLocation = loc
+	}
+	return nil
+}
+
+// 获取软删除列使用的时间格式, 字段为空指针时也可以获取
+func (m *Mapper) GetSoftDeleteTimeFormat() (TimeFormat, error) {
+	f, ok := m.fm.get(m.softDelete)
+	if !ok || f.tag == nil {
+		return TimeFormat{}, nil
+	}
+	field := &Field{tag: f.tag, tagString: f.tagString}
+	if err := m.decideTimeFormat(field); err != nil {
+		return TimeFormat{}, err
+	}
+	return TimeFormat{Layout: field.timeLayout, Location: field.timeLocation}, nil
+}
+
+// 时间字段的值和写入使用的格式
+func (f *Field) timeValue() (t time.Time, layout string, ok bool) {
+	layout = f.timeLayout
+	switch v := f.addr.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		t = *v
+	case Time:
+		t = v.Time
+		if v.layout != "" {
+			layout = v.layout
+		}
+	case *Time:
+		t = v.Time
+		if v.layout != "" {
+			layout = v.layout
+		}
+	default:
+		return t, layout, false
+	}
+	return t, layout, true
+}
+
+// 格式化写入的时间
+// 字段的值不是支持的时间类型时返回TimeValueError
+func (f *Field) formatTime() (interface{}, error) {
+	t, layout, ok := f.timeValue()
+	if !ok {
+		return nil, fmt.Errorf("column %s: %w", f.tagString, define.TimeValueError)
+	}
+	if f.timeLocation != nil {
+		t = t.In(f.timeLocation)
+	}
+	return t.Format(layout), nil
+}
+
+// 解析读取的时间, NULL, 空字符串和mysql的零值日期解析为零值
+func (f *Field) parseTime() error {
+	var t time.Time
+	s := string(f.raw)
+	if s != "" && !strings.HasPrefix(s, "0000-00-00") {
+		loc := f.timeLocation
+		if loc == nil {
+			loc = time.UTC
+		}
+		var err error
+		if t, err = time.ParseInLocation(f.timeLayout, s, loc); err != nil {
+			for _, layout := range fallbackTimeLayouts {
+				if t, err = time.ParseInLocation(layout, s, loc); err == nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("parse time column %s: %w", f.tagString, err)
+		}
+	}
+	switch v := f.addr.(type) {
+	case *time.Time:
+		*v = t
+	case *Time:
+		v.Time = t
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package mapper
+
+import (
+	"reflect"
+	"time"
+)
+
+// 没有声明auto_create_time, auto_update_time时, 按照字段名识别自动时间字段
+const (
+	createdAtField = "CreatedAt"
+	updatedAtField = "UpdatedAt"
+)
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	timePtrType = reflect.TypeOf(&time.Time{})
+	// mapper.Time
+	mapperTimeType    = reflect.TypeOf(Time{})
+	mapperTimePtrType = reflect.TypeOf(&Time{})
+)
+
+// 可以作为自动时间字段的类型
+func isTimestampType(t reflect.Type) bool {
+	return t == timeType || t == timePtrType || t == mapperTimeType || t == mapperTimePtrType
+}
+
+// 为自动时间字段赋值, 需要在Parse之前调用, 只处理struct指针的顶层字段
+// 插入时为零值的创建时间和更新时间赋值, 更新时总是为更新时间赋值
+func (m *Mapper) SetTimestamps(parseType int, now time.Time) {
+	if !m.ptr {
+		return
+	}
+	for i, n := 0, m.ot.NumField(); i < n; i++ {
+		ff := m.ot.Field(i)
+		fv := m.opv.Field(i)
+		if !isTimestampType(ff.Type) {
+			continue
+		}
+		tag := newTag(&ff, &fv).parse(m.style.column, m.syntax)
+		if tag.ignore || !fv.CanSet() {
+			continue
+		}
+		create := tag.autoCreate || (!tag.autoUpdate && ff.Name == createdAtField)
+		update := tag.isAutoUpdateTime()
+		switch parseType {
+		case PARSE_INSERT:
+			if (create || update) && isZeroTime(fv) {
+				setTime(fv, now)
+			}
+		case PARSE_UPDATE:
+			if update {
+				setTime(fv, now)
+			}
+		}
+	}
+}
+
+func isZeroTime(fv reflect.Value) bool {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return true
+		}
+		fv = fv.Elem()
+	}
+	if t, ok := fv.Interface().(Time); ok {
+		return t.IsZero()
+	}
+	return fv.Interface().(time.Time).IsZero()
+}
+
+// 指针字段赋值为新的指针, mapper.Time保留字段原有的格式
+func setTime(fv reflect.Value, now time.Time) {
+	if fv.Kind() == reflect.Ptr {
+		value := reflect.New(fv.Type().Elem())
+		if !fv.IsNil() {
+			value.Elem().Set(fv.Elem())
+		}
+		setTime(value.Elem(), now)
+		fv.Set(value)
+		return
+	}
+	if fv.Type() == mapperTimeType {
+		fv.Field(0).Set(reflect.ValueOf(now))
+		return
+	}
+	fv.Set(reflect.ValueOf(now))
+}
+
+// 是否是更新时自动写入时间的字段
+func (t *Tag) isAutoUpdateTime() bool {
+	if t.autoUpdate {
+		return true
+	}
+	return !t.autoCreate && t.fieldName == updatedAtField && isTimestampType(t.fieldType)
+}
@@ -0,0 +1,55 @@
+package sprydb
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+type nullableUser struct {
+	Id       int64      `spry:"col:id"`
+	Nickname *string    `spry:"col:nickname"`
+	Age      *int       `spry:"col:age"`
+	LoginAt  *time.Time `spry:"col:login_at"`
+}
+
+func (nullableUser) Table() string { return "users" }
+
+func TestSession_NullablePointer(t *testing.T) {
+	conn, d := newFakeConnection(t, []string{"id", "nickname", "age", "login_at"}, [][]driver.Value{
+		{int64(1), "foo", int64(0), []byte("2018-01-02 03:04:05")},
+		{int64(2), nil, nil, nil},
+		{int64(3), "bar", int64(20), nil},
+	})
+
+	var users []nullableUser
+	if err := conn.Table("users").Get(&users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 3 {
+		t.Fatalf("Get error: %v", users)
+	}
+	first, second, third := users[0], users[1], users[2]
+	if first.Nickname == nil || *first.Nickname != "foo" || first.Age == nil || *first.Age != 0 ||
+		first.LoginAt == nil || first.LoginAt.Year() != 2018 {
+		t.Errorf("non-NULL columns error: %+v", first)
+	}
+	if second.Nickname != nil || second.Age != nil || second.LoginAt != nil {
+		t.Errorf("NULL columns should be nil: %+v", second)
+	}
+	// 每一行分配新的值
+	if third.Nickname == first.Nickname || *first.Nickname != "foo" || *third.Age != 20 {
+		t.Errorf("rows share pointers: %+v %+v", first, third)
+	}
+
+	age := 0
+	if _, err := conn.Where("id", "=", 1).Update(&nullableUser{Age: &age}); err != nil {
+		t.Fatal(err)
+	}
+	if sqlStr := d.execs[0]; sqlStr != "update `users` set `nickname` = ?,`age` = ?,`login_at` = ? where `id` = ?" {
+		t.Errorf("update sql error: %s", sqlStr)
+	}
+	if args := d.args[len(d.args)-1]; len(args) != 4 || args[0] != nil || args[1] != int64(0) || args[2] != nil {
+		t.Errorf("update bindings error: %v", args)
+	}
+}
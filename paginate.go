@@ -0,0 +1,183 @@
+package sprydb
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/Soul-Mate/sprydb/define"
+)
+
+// 游标中保存的时间格式, 可以直接与datetime列比较
+const cursorTimeLayout = "2006-01-02 15:04:05.999999"
+
+// 游标的内容, 编码为base64之后返回给调用者
+type cursorToken struct {
+	Values   []interface{} `json:"v"`
+	Backward bool          `json:"b,omitempty"`
+}
+
+// 基于游标的分页, 根据排序列生成 where (a, b) > (?, ?) 条件, 不需要扫描offset之前的数据
+// 调用前需要使用OrderBy设置排序, 排序列的组合需要唯一, 通常最后一列是主键, 所有排序列的方向需要相同
+// cursor为空时查询第一页, 返回的next和prev是下一页和上一页的游标, 没有更多数据时为空
+func (s *Session) CursorPaginate(perPage int, cursor string, dest interface{}, column ...string) (next, prev string, err error) {
+	var token *cursorToken
+
+	defer s.resetBuilder()
+
+	if perPage <= 0 {
+		return "", "", define.PerPageError
+	}
+
+	if dest == nil {
+		return "", "", define.ObjectNoneError
+	}
+
+	reflectType := reflect.TypeOf(dest)
+	if reflectType.Kind() != reflect.Ptr || reflectType.Elem().Kind() != reflect.Slice ||
+		reflectType.Elem().Elem().Kind() != reflect.Struct {
+		return "", "", define.UnsupportedTypeError
+	}
+	elem := reflect.ValueOf(dest).Elem()
+
+	orderColumns, direction := s.queryBuilder.GetOrders()
+	if len(orderColumns) <= 0 {
+		return "", "", define.CursorOrderNoneError
+	}
+	// 行比较 (a, b) > (?, ?) 只能表示相同方向的排序
+	if direction == "" {
+		return "", "", define.CursorOrderDirectionError
+	}
+
+	if cursor != "" {
+		if token, err = decodeCursor(cursor); err != nil {
+			return "", "", err
+		}
+		if len(token.Values) != len(orderColumns) {
+			return "", "", define.InvalidCursorError
+		}
+		operator := ">"
+		if direction == "desc" {
+			operator = "<"
+		}
+		// 向前翻页时反向查询, 得到结果之后再反转
+		if token.Backward {
+			if operator == ">" {
+				operator = "<"
+			} else {
+				operator = ">"
+			}
+			s.queryBuilder.ReverseOrders()
+		}
+		s.queryBuilder.WhereRow(orderColumns, operator, token.Values)
+	}
+
+	// 多查询一条用来判断是否还有数据
+	s.queryBuilder.Take(perPage + 1)
+	elem.Set(reflect.MakeSlice(elem.Type(), 0, perPage+1))
+	if err = s.Get(dest, column...); err != nil {
+		return "", "", err
+	}
+
+	n := elem.Len()
+	more := n > perPage
+	if more {
+		n = perPage
+		elem.Set(elem.Slice(0, n))
+	}
+
+	backward := token != nil && token.Backward
+	if backward {
+		swap := reflect.Swapper(elem.Interface())
+		for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	if n <= 0 {
+		return "", "", nil
+	}
+
+	hasNext, hasPrev := more, token != nil
+	if backward {
+		hasNext, hasPrev = true, more
+	}
+
+	if hasNext {
+		if next, err = s.encodeCursor(elem.Index(n-1), orderColumns, false); err != nil {
+			return "", "", err
+		}
+	}
+	if hasPrev {
+		if prev, err = s.encodeCursor(elem.Index(0), orderColumns, true); err != nil {
+			return "", "", err
+		}
+	}
+	return next, prev, nil
+}
+
+// 使用对象中排序列的值生成游标
+func (s *Session) encodeCursor(object reflect.Value, columns []string, backward bool) (string, error) {
+	item := reflect.New(object.Type())
+	item.Elem().Set(object)
+	itemMapper, err := s.parseModel(item.Interface())
+	if err != nil {
+		return "", err
+	}
+
+	token := cursorToken{Backward: backward}
+	for _, c := range columns {
+		value, ok := itemMapper.GetValueByColumn(c)
+		if !ok || value == nil {
+			return "", define.CursorColumnError
+		}
+		if t, ok := value.(time.Time); ok {
+			value = t.Format(cursorTimeLayout)
+		}
+		token.Values = append(token.Values, value)
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(cursor string) (*cursorToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, define.InvalidCursorError
+	}
+
+	token := new(cursorToken)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(token); err != nil {
+		return nil, define.InvalidCursorError
+	}
+
+	// json的数字转换为驱动支持的类型, 整数不能损失精度
+	for i, v := range token.Values {
+		switch v := v.(type) {
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				token.Values[i] = n
+			} else if n, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+				// 超过int64范围的uint64, 例如snowflake id
+				token.Values[i] = n
+			} else if f, err := v.Float64(); err == nil {
+				token.Values[i] = f
+			} else {
+				return nil, define.InvalidCursorError
+			}
+		case string, bool:
+		default:
+			return nil, define.InvalidCursorError
+		}
+	}
+	return token, nil
+}
@@ -0,0 +1,106 @@
+package sprydb
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/Soul-Mate/sprydb/define"
+)
+
+func TestSession_CursorPaginate(t *testing.T) {
+	columns, rows := fakeUserRows()
+	conn, d := newFakeConnection(t, columns, nil)
+	d.results = [][][]driver.Value{
+		rows,
+		rows[2:],
+		{rows[1], rows[0]},
+	}
+
+	var users []fakeUser
+	next, prev, err := conn.Table("users").OrderBy("id", "asc").CursorPaginate(2, "", &users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || next == "" || prev != "" {
+		t.Fatalf("first page error: %v %q %q", users, next, prev)
+	}
+	if want := "select `id`,`name` from `users` order by `id` asc limit 3"; d.queries[0] != want {
+		t.Errorf("first page sql error: %s", d.queries[0])
+	}
+
+	next, prev, err = conn.Table("users").OrderBy("id", "asc").CursorPaginate(2, next, &users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].Id != 3 || next != "" || prev == "" {
+		t.Fatalf("second page error: %v %q %q", users, next, prev)
+	}
+	if want := "select `id`,`name` from `users` where (`id`) > (?) order by `id` asc limit 3"; d.queries[1] != want {
+		t.Errorf("second page sql error: %s", d.queries[1])
+	}
+	if len(d.args[1]) != 1 || d.args[1][0] != int64(2) {
+		t.Errorf("second page bindings error: %v", d.args[1])
+	}
+
+	// 向前翻页时反向查询, 结果恢复为原来的顺序
+	next, prev, err = conn.Table("users").OrderBy("id", "asc").CursorPaginate(2, prev, &users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0].Id != 1 || users[1].Id != 2 || next == "" || prev != "" {
+		t.Fatalf("previous page error: %v %q %q", users, next, prev)
+	}
+	if want := "select `id`,`name` from `users` where (`id`) < (?) order by `id` desc limit 3"; d.queries[2] != want {
+		t.Errorf("previous page sql error: %s", d.queries[2])
+	}
+	assertNoConnectionInUse(t, conn, "CursorPaginate")
+
+	if _, _, err = conn.Table("users").CursorPaginate(2, "", &users); err != define.CursorOrderNoneError {
+		t.Errorf("CursorPaginate without order should fail, got %v", err)
+	}
+	if _, _, err = conn.Table("users").OrderBy("id", "asc").CursorPaginate(2, "!", &users); err != define.InvalidCursorError {
+		t.Errorf("CursorPaginate with invalid cursor should fail, got %v", err)
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	values := []interface{}{uint64(math.MaxUint64 - 1), int64(-3), 1.5, "foo"}
+	data, err := json.Marshal(cursorToken{Values: values})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := decodeCursor(base64.RawURLEncoding.EncodeToString(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(token.Values, values) {
+		t.Errorf("decodeCursor lost precision: %#v", token.Values)
+	}
+}
+
+func TestSession_CursorPaginateMixedOrder(t *testing.T) {
+	columns, rows := fakeUserRows()
+	conn, d := newFakeConnection(t, columns, rows)
+
+	var users []fakeUser
+	_, _, err := conn.Table("users").OrderBy("name", "desc").OrderBy("id", "asc").CursorPaginate(2, "", &users)
+	if err != define.CursorOrderDirectionError {
+		t.Errorf("CursorPaginate with mixed directions should fail, got %v", err)
+	}
+
+	// 反向查询时每一列都反转方向
+	_, prev, err := conn.Table("users").OrderByMulti([]string{"name", "id"}, "desc").CursorPaginate(2, "", &users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "select `id`,`name` from `users` order by `name` desc,`id` desc limit 3"; d.queries[0] != want {
+		t.Errorf("first page sql error: %s", d.queries[0])
+	}
+	if prev != "" {
+		t.Errorf("first page should not have previous cursor: %q", prev)
+	}
+}
@@ -2,6 +2,7 @@ package query
 
 import (
 	"github.com/Soul-Mate/sprydb/binding"
+	"github.com/Soul-Mate/sprydb/mapper"
 	"github.com/Soul-Mate/sprydb/syntax"
 	"strconv"
 )
@@ -20,8 +21,17 @@ type Builder struct {
 	orders     map[string]interface{}
 	limit      string
 	offset     string
+	aggregate  string
+	softDelete string // 软删除的列
+	trashed    int    // 软删除的查询范围
 	binding    *binding.Binding
 	syntax     syntax.Syntax
+
+	// 软删除时写入时间的格式
+	softDeleteFormat mapper.TimeFormat
+
+	// 更新对象时只更新这些列, 零值也会更新
+	updateColumns []string
 }
 
 func NewBuilder(driver string, syntax syntax.Syntax, binding *binding.Binding) *Builder {
@@ -38,6 +48,7 @@ func NewBuilder(driver string, syntax syntax.Syntax, binding *binding.Binding) *
 	b.orders = make(map[string]interface{})
 	b.orders["direction"] = ""
 	b.orders["column"] = []string{}
+	b.orders["directions"] = []string{}
 	b.limit = ""
 	b.offset = ""
 	b.binding = binding
@@ -77,6 +88,13 @@ func (b *Builder) Take(n int) *Builder {
 	return b
 }
 
+// 聚合查询, 例如Aggregate("count", "*")生成 select count(*)
+func (b *Builder) Aggregate(function, column string) *Builder {
+	b.aggregate = function
+	b.column = []string{column}
+	return b
+}
+
 func (b *Builder) GetTable() string {
 	return b.tableName
 }
@@ -103,4 +121,41 @@ func (b *Builder) GetJoinMap() map[string]string {
 
 func (b *Builder) GetErr() error {
 	return b.err
-}
\ No newline at end of file
+}
+
+func (b *Builder) SetErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// 更新对象时只更新指定的列, 列的零值也会更新
+func (b *Builder) UpdateColumns(columns ...string) *Builder {
+	b.updateColumns = columns
+	return b
+}
+
+func (b *Builder) GetUpdateColumns() []string {
+	return b.updateColumns
+}
+
+// 复制builder的查询状态, 复制后的builder使用binding保存参数
+// 用于同一个查询需要多次执行的场景, 例如分块查询
+func (b *Builder) Clone(binding *binding.Binding) *Builder {
+	c := *b
+	c.binding = binding
+	c.column = append([]string{}, b.column...)
+	c.joins = append([]*BuilderJoin{}, b.joins...)
+	c.wheres = append([]map[string]interface{}{}, b.wheres...)
+	c.joinMap = make(map[string]string, len(b.joinMap))
+	for k, v := range b.joinMap {
+		c.joinMap[k] = v
+	}
+	c.orders = make(map[string]interface{}, len(b.orders))
+	for k, v := range b.orders {
+		c.orders[k] = v
+	}
+	c.orders["column"] = append([]string{}, b.orders["column"].([]string)...)
+	c.orders["directions"] = append([]string{}, b.orders["directions"].([]string)...)
+	return &c
+}
@@ -23,4 +23,41 @@ func (b *Builder) orderBy(direction string, col ...string) {
 	}
 	b.orders["direction"] = direction
 	b.orders["column"] = append(b.orders["column"].([]string), col...)
+	// 每一列使用各自的排序方向
+	directions := b.orders["directions"].([]string)
+	for range col {
+		directions = append(directions, direction)
+	}
+	b.orders["directions"] = directions
+}
+
+// 获取排序的列和方向, 列的排序方向不一致时direction为空
+func (b *Builder) GetOrders() (columns []string, direction string) {
+	columns = b.orders["column"].([]string)
+	for i, d := range b.orders["directions"].([]string) {
+		if i > 0 && d != direction {
+			return columns, ""
+		}
+		direction = d
+	}
+	return columns, direction
+}
+
+// 反转每一列的排序方向
+func (b *Builder) ReverseOrders() *Builder {
+	directions := b.orders["directions"].([]string)
+	reversed := make([]string, len(directions))
+	for i, d := range directions {
+		reversed[i] = reverseDirection(d)
+	}
+	b.orders["directions"] = reversed
+	b.orders["direction"] = reverseDirection(b.orders["direction"].(string))
+	return b
+}
+
+func reverseDirection(direction string) string {
+	if direction == "desc" {
+		return "asc"
+	}
+	return "desc"
 }
@@ -0,0 +1,40 @@
+package query
+
+import "github.com/Soul-Mate/sprydb/mapper"
+
+// 软删除的查询范围
+const (
+	TrashedExclude = iota // 默认, 只查询没有被软删除的数据
+	TrashedWith           // 包括被软删除的数据
+	TrashedOnly           // 只查询被软删除的数据
+)
+
+// 设置软删除的列, 查询, 更新和删除会根据查询范围自动增加条件
+func (b *Builder) SoftDelete(column string) *Builder {
+	b.softDelete = column
+	return b
+}
+
+func (b *Builder) GetSoftDelete() string {
+	return b.softDelete
+}
+
+// 设置软删除时写入时间使用的格式和时区
+func (b *Builder) SoftDeleteTimeFormat(format mapper.TimeFormat) *Builder {
+	b.softDeleteFormat = format
+	return b
+}
+
+func (b *Builder) GetSoftDeleteTimeFormat() mapper.TimeFormat {
+	return b.softDeleteFormat
+}
+
+func (b *Builder) WithTrashed() *Builder {
+	b.trashed = TrashedWith
+	return b
+}
+
+func (b *Builder) OnlyTrashed() *Builder {
+	b.trashed = TrashedOnly
+	return b
+}
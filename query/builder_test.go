@@ -49,7 +49,7 @@ func TestBuilder_Select(t *testing.T) {
 func TestBuilder_Where(t *testing.T) {
 	syntax2  := syntax.NewSyntax("mysql")
 	binding2 := binding.NewBinding()
-	grammar2 := NewGrammarFactory("mysql", syntax2, binding2)
+	grammar2 := NewGrammarFactory("mysql", syntax2, binding2, nil)
 	rawSQL := "select * from `users` where `id` > ? and `name` = ?"
 	b := NewBuilder("mysql", syntax2, binding2)
 	b.Table("users").Where("id", ">", 1).
@@ -93,7 +93,7 @@ func TestBuilder_Where(t *testing.T) {
 func TestBuilder_OrWhere(t *testing.T) {
 	syntax2  := syntax.NewSyntax("mysql")
 	binding2 := binding.NewBinding()
-	grammar2 := NewGrammarFactory("mysql", syntax2, binding2)
+	grammar2 := NewGrammarFactory("mysql", syntax2, binding2, nil)
 	rawSQL := "select * from `users` where `id` > ? or `name` = ?"
 	b := NewBuilder("mysql", syntax2, binding2)
 	b.Table("users").Where("id", ">", 1).
@@ -137,7 +137,7 @@ func TestBuilder_OrWhere(t *testing.T) {
 func TestBuilder_WhereIn(t *testing.T) {
 	syntax2  := syntax.NewSyntax("mysql")
 	binding2 := binding.NewBinding()
-	grammar2 := NewGrammarFactory("mysql", syntax2, binding2)
+	grammar2 := NewGrammarFactory("mysql", syntax2, binding2, nil)
 	rawSQL := "select * from `users` where in (?,?,?,?,?) and in (?,?,?)"
 	b := NewBuilder("mysql", syntax2, binding2)
 	b.Table("users").WhereIn("id", 1, 2, 3, 4, 5).
@@ -250,7 +250,7 @@ func TestBuilder_OrderByMulti(t *testing.T) {
 func TestBuilder_Join(t *testing.T) {
 	syntax2  := syntax.NewSyntax("mysql")
 	binding2 := binding.NewBinding()
-	grammar2 := NewGrammarFactory("mysql", syntax2, binding2)
+	grammar2 := NewGrammarFactory("mysql", syntax2, binding2, nil)
 	rawSQL := "select * from `users` inner join `levels` on `users`.`id` = `levels`.`user_id`"
 	b := NewBuilder("mysql", syntax2, binding2)
 	b.Table("users").Join("levels", "users.id", "=", "levels.user_id")
@@ -266,7 +266,7 @@ func TestBuilder_Join(t *testing.T) {
 func TestBuilder_JoinClosure(t *testing.T) {
 	syntax2  := syntax.NewSyntax("mysql")
 	binding2 := binding.NewBinding()
-	grammar2 := NewGrammarFactory("mysql", syntax2, binding2)
+	grammar2 := NewGrammarFactory("mysql", syntax2, binding2, nil)
 	rawSQL := "select * from `users` inner join `levels` on `levels`.`user_id` = `users`.`id` " +
 		"or `levels`.`user_name` = `users`.`name` " +
 		"and `levels`.`user_id` = `users`.`id` and id > ? " +
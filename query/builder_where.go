@@ -116,6 +116,14 @@ func (b *Builder) OrWhereNotNull(column string) *Builder {
 	return b
 }
 
+// 行比较条件, 例如 (a, b) > (?, ?)
+func (b *Builder) WhereRow(columns []string, operator string, parameters []interface{}) *Builder {
+	if err := b.whereRow(columns, operator, parameters, "and"); err != nil {
+		b.err = err
+	}
+	return b
+}
+
 func (b *Builder) WhereSub(column, operator string, f func(b *Builder)) *Builder {
 	b.whereSub(column, operator, "and", f)
 	return b
@@ -153,6 +161,24 @@ func (b *Builder) whereBetween(column string, first, last interface{}, logic str
 	return
 }
 
+func (b *Builder) whereRow(columns []string, operator string, parameters []interface{}, logic string) (err error) {
+	if len(columns) <= 0 || len(columns) != len(parameters) {
+		return errors.New("whereQuery row columns and parameters must have the same length")
+	}
+	if operator, err = b.syntax.PrepareWhereOperator(operator); err != nil {
+		return
+	}
+	b.wheres = append(b.wheres, map[string]interface{}{
+		"type":     "Row",
+		"columns":  columns,
+		"operator": operator,
+		"value":    parameters,
+		"logic":    logic,
+	})
+	b.binding.AddBinding("where", parameters)
+	return
+}
+
 func (b *Builder) whereNull(column, logic string, not bool) {
 	b.wheres = append(b.wheres, map[string]interface{}{
 		"type":   "Null",
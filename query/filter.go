@@ -0,0 +1,273 @@
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Soul-Mate/sprydb/define"
+)
+
+// 过滤条件中的操作符与sql操作符的对应关系
+// in, nin, between, null不是比较操作符, 单独处理
+var filterOperators = map[string]string{
+	"eq":      "=",
+	"ne":      "!=",
+	"gt":      ">",
+	"gte":     ">=",
+	"lt":      "<",
+	"lte":     "<=",
+	"like":    "like",
+	"notlike": "not like",
+}
+
+// 没有设置FilterSpec.MaxLimit时允许的最大limit
+const DefaultFilterMaxLimit = 100
+
+// 解码后的过滤条件
+// Filter的key是列名, 值是操作符到值的映射, 或者直接是值(等同于eq)
+// Sort是逗号分隔的列名, 列名以-开头表示降序, 每一列可以使用不同的方向, 例如 -created_at,id
+// Limit为0或者超过MaxLimit时使用MaxLimit, 客户端不能查询没有限制的数据
+type FilterSpec struct {
+	Filter map[string]interface{} `json:"filter"`
+	Sort   string                 `json:"sort"`
+	Limit  int                    `json:"limit"`
+	Offset int                    `json:"offset"`
+	// 由服务端设置, 不从json或url参数中解析, 为0时使用DefaultFilterMaxLimit, 小于0时不限制
+	MaxLimit int `json:"-"`
+}
+
+// 解析url参数, 例如 filter[status]=active&filter[age][gt]=18&sort=-created_at&limit=10&offset=20
+func ParseFilterSpec(values url.Values) (*FilterSpec, error) {
+	var err error
+	spec := &FilterSpec{Filter: make(map[string]interface{})}
+	for key, vs := range values {
+		if len(vs) <= 0 {
+			continue
+		}
+		value := vs[len(vs)-1]
+		switch {
+		case key == "sort":
+			spec.Sort = value
+		case key == "limit":
+			if spec.Limit, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("%w: limit", define.FilterValueError)
+			}
+		case key == "offset":
+			if spec.Offset, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("%w: offset", define.FilterValueError)
+			}
+		case strings.HasPrefix(key, "filter["):
+			column, operator, ok := parseFilterKey(key)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", define.FilterValueError, key)
+			}
+			ops, _ := spec.Filter[column].(map[string]interface{})
+			if ops == nil {
+				ops = make(map[string]interface{})
+				spec.Filter[column] = ops
+			}
+			ops[operator] = value
+		}
+	}
+	return spec, nil
+}
+
+// 解析 filter[column] 或者 filter[column][operator]
+func parseFilterKey(key string) (column, operator string, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(key, "filter"), "]")
+	if parts[len(parts)-1] != "" {
+		return "", "", false
+	}
+	parts = parts[:len(parts)-1]
+	if len(parts) < 1 || len(parts) > 2 {
+		return "", "", false
+	}
+	for i, p := range parts {
+		if !strings.HasPrefix(p, "[") || len(p) < 2 {
+			return "", "", false
+		}
+		parts[i] = p[1:]
+	}
+	if len(parts) == 1 {
+		return parts[0], "eq", true
+	}
+	return parts[0], parts[1], true
+}
+
+// 根据过滤条件生成应用到builder的函数
+// 只允许allowedColumns中的列, 不允许的列和操作符会设置builder的错误
+func FromFilterSpec(spec *FilterSpec, allowedColumns []string) func(b *Builder) {
+	allowed := make(map[string]bool, len(allowedColumns))
+	for _, c := range allowedColumns {
+		allowed[c] = true
+	}
+	return func(b *Builder) {
+		if spec == nil || b.err != nil {
+			return
+		}
+		if err := applyFilterSpec(b, spec, allowed); err != nil {
+			b.err = err
+		}
+	}
+}
+
+func applyFilterSpec(b *Builder, spec *FilterSpec, allowed map[string]bool) error {
+	// 按列名排序, 保证生成的sql和参数顺序稳定
+	columns := make([]string, 0, len(spec.Filter))
+	for column := range spec.Filter {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	for _, column := range columns {
+		if !allowed[column] {
+			return fmt.Errorf("%w: %s", define.FilterColumnError, column)
+		}
+		ops, ok := spec.Filter[column].(map[string]interface{})
+		if !ok {
+			ops = map[string]interface{}{"eq": spec.Filter[column]}
+		}
+		names := make([]string, 0, len(ops))
+		for name := range ops {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := applyFilter(b, column, name, ops[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if spec.Sort != "" {
+		for _, column := range strings.Split(spec.Sort, ",") {
+			direction := "asc"
+			if column = strings.TrimSpace(column); strings.HasPrefix(column, "-") {
+				column, direction = column[1:], "desc"
+			}
+			if !allowed[column] {
+				return fmt.Errorf("%w: %s", define.FilterColumnError, column)
+			}
+			b.OrderBy(column, direction)
+		}
+	}
+
+	if spec.Limit < 0 || spec.Offset < 0 {
+		return fmt.Errorf("%w: limit and offset cannot be negative", define.FilterValueError)
+	}
+	if limit := filterLimit(spec); limit > 0 {
+		b.Take(limit)
+	}
+	if spec.Offset > 0 {
+		b.Skip(spec.Offset)
+	}
+	return nil
+}
+
+// 限制客户端可以查询的数据量
+func filterLimit(spec *FilterSpec) int {
+	maxLimit := spec.MaxLimit
+	if maxLimit == 0 {
+		maxLimit = DefaultFilterMaxLimit
+	}
+	if maxLimit < 0 {
+		return spec.Limit
+	}
+	if spec.Limit <= 0 || spec.Limit > maxLimit {
+		return maxLimit
+	}
+	return spec.Limit
+}
+
+func applyFilter(b *Builder, column, name string, value interface{}) error {
+	switch name {
+	case "in", "nin":
+		values, err := filterValues(value)
+		if err != nil || len(values) <= 0 {
+			return fmt.Errorf("%w: %s[%s]", define.FilterValueError, column, name)
+		}
+		if name == "in" {
+			b.WhereIn(column, values...)
+		} else {
+			b.WhereNotIn(column, values...)
+		}
+	case "between":
+		values, err := filterValues(value)
+		if err != nil || len(values) != 2 {
+			return fmt.Errorf("%w: %s[%s]", define.FilterValueError, column, name)
+		}
+		b.WhereBetween(column, values[0], values[1])
+	case "null":
+		isNull, err := filterBool(value)
+		if err != nil {
+			return fmt.Errorf("%w: %s[%s]", define.FilterValueError, column, name)
+		}
+		if isNull {
+			b.WhereNull(column)
+		} else {
+			b.WhereNotNull(column)
+		}
+	default:
+		operator, ok := filterOperators[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", define.InvalidOperatorError, name)
+		}
+		if !isFilterScalar(value) {
+			return fmt.Errorf("%w: %s[%s]", define.FilterValueError, column, name)
+		}
+		if operator, err := b.syntax.PrepareWhereOperator(operator); err != nil {
+			return err
+		} else {
+			b.Where(column, operator, value)
+		}
+	}
+	return b.err
+}
+
+// 多个值可以是slice或者逗号分隔的字符串
+func filterValues(value interface{}) ([]interface{}, error) {
+	if s, ok := value.(string); ok {
+		var values []interface{}
+		for _, v := range strings.Split(s, ",") {
+			values = append(values, v)
+		}
+		return values, nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice {
+		return nil, define.FilterValueError
+	}
+	values := make([]interface{}, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+		if !isFilterScalar(values[i]) {
+			return nil, define.FilterValueError
+		}
+	}
+	return values, nil
+}
+
+func filterBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(v)
+	}
+	return false, define.FilterValueError
+}
+
+// 只允许可以直接绑定的基本类型
+func isFilterScalar(value interface{}) bool {
+	switch value.(type) {
+	case int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64, string, bool:
+		return true
+	}
+	return false
+}
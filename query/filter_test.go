@@ -0,0 +1,91 @@
+package query
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/Soul-Mate/sprydb/binding"
+	"github.com/Soul-Mate/sprydb/define"
+	"github.com/Soul-Mate/sprydb/syntax"
+)
+
+func TestFromFilterSpec(t *testing.T) {
+	values, _ := url.ParseQuery("filter[status]=active&filter[age][gt]=18&filter[role][in]=admin,owner" +
+		"&filter[deleted_at][null]=true&sort=-created_at,-id&limit=10&offset=20")
+	spec, err := ParseFilterSpec(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	syntax2 := syntax.NewSyntax("mysql")
+	binding2 := binding.NewBinding()
+	grammar := NewGrammarFactory("mysql", syntax2, binding2, nil)
+	b := NewBuilder("mysql", syntax2, binding2)
+	FromFilterSpec(spec, []string{"status", "age", "role", "deleted_at", "created_at", "id"})(b.Table("users"))
+	if err = b.GetErr(); err != nil {
+		t.Fatal(err)
+	}
+
+	sql, err := grammar.CompileSelect(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "select * from `users` where `age` > ? and `deleted_at` is null and `role` in (?,?) and `status` = ? " +
+		"order by `created_at` desc,`id` desc limit 10 offset 20"
+	if sql != want {
+		t.Errorf("TestFromFilterSpec sql error: %s", sql)
+	}
+	bindings := binding2.GetBindings()
+	if len(bindings) != 4 || bindings[0] != "18" || bindings[2] != "owner" || bindings[3] != "active" {
+		t.Errorf("TestFromFilterSpec bindings error: %v", bindings)
+	}
+}
+
+func TestFromFilterSpec_Reject(t *testing.T) {
+	cases := []struct {
+		spec *FilterSpec
+		err  error
+	}{
+		{&FilterSpec{Filter: map[string]interface{}{"password": "x"}}, define.FilterColumnError},
+		{&FilterSpec{Filter: map[string]interface{}{"name": map[string]interface{}{"or 1=1": "x"}}}, define.InvalidOperatorError},
+		{&FilterSpec{Filter: map[string]interface{}{"name": map[string]interface{}{"eq": []int{1}}}}, define.FilterValueError},
+		{&FilterSpec{Sort: "name; drop table users"}, define.FilterColumnError},
+		{&FilterSpec{Limit: -1}, define.FilterValueError},
+	}
+	for _, c := range cases {
+		b := NewBuilder("mysql", syntax.NewSyntax("mysql"), binding.NewBinding())
+		FromFilterSpec(c.spec, []string{"name", "id"})(b)
+		if !errors.Is(b.GetErr(), c.err) {
+			t.Errorf("TestFromFilterSpec_Reject %+v: got %v, want %v", c.spec, b.GetErr(), c.err)
+		}
+	}
+}
+
+func TestFromFilterSpec_SortLimit(t *testing.T) {
+	cases := []struct {
+		spec   *FilterSpec
+		rawSQL string
+	}{
+		{&FilterSpec{Sort: "-created_at,id", Limit: 20}, "select * from `users` order by `created_at` desc,`id` asc limit 20"},
+		// 没有limit或者超过最大值时使用最大值
+		{&FilterSpec{}, "select * from `users` limit 100"},
+		{&FilterSpec{Limit: 1000}, "select * from `users` limit 100"},
+		{&FilterSpec{Limit: 1000, MaxLimit: 500}, "select * from `users` limit 500"},
+		{&FilterSpec{MaxLimit: -1}, "select * from `users`"},
+	}
+	for _, c := range cases {
+		syntax2 := syntax.NewSyntax("mysql")
+		binding2 := binding.NewBinding()
+		grammar := NewGrammarFactory("mysql", syntax2, binding2, nil)
+		b := NewBuilder("mysql", syntax2, binding2)
+		FromFilterSpec(c.spec, []string{"created_at", "id"})(b.Table("users"))
+		sql, err := grammar.CompileSelect(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sql != c.rawSQL {
+			t.Errorf("TestFromFilterSpec_SortLimit error: %s", sql)
+		}
+	}
+}
@@ -18,9 +18,12 @@ type GrammarInterface interface {
 	CompileJoin(joins []*BuilderJoin) string
 	CompileWhere(wheres []map[string]interface{}, removeLeading bool) string
 	CompileOrderBy(orders map[string]interface{}) string
+	CompileSoftDeleteScope(builder *Builder) string
 	CompileInsert(object interface{}, builder *Builder) (sqlStr string, bindings []interface{}, err error)
 	CompileUpdate(value interface{}, builder *Builder) (string, []interface{}, error)
+	CompileIncrement(column, operator string, amount interface{}, extra map[string]interface{}, builder *Builder) (string, []interface{}, error)
 	CompileDelete(builder *Builder) (sqlStr string, err error)
+	SetTimeFormat(format *mapper.TimeFormat)
 }
 
 func NewGrammarFactory(driver string, syntax syntax.Syntax, binding *binding.Binding, styler mapper.MapperStyler) GrammarInterface {
@@ -37,6 +40,7 @@ type Grammar struct {
 	styler       mapper.MapperStyler
 	binding      *binding.Binding
 	selectSqlMap map[string]string
+	timeFormat   *mapper.TimeFormat
 }
 
 var SelectStep = []string{
@@ -52,6 +56,11 @@ func NewGrammar(syntax syntax.Syntax, binding *binding.Binding, styler mapper.Ma
 	}
 }
 
+// 设置插入和更新对象时使用的时间格式
+func (g *Grammar) SetTimeFormat(format *mapper.TimeFormat) {
+	g.timeFormat = format
+}
+
 func (g *Grammar) CompileSelect(builder *Builder) (string, error) {
 	var (
 		column, from, join, where, order, offset string
@@ -60,10 +69,10 @@ func (g *Grammar) CompileSelect(builder *Builder) (string, error) {
 	if builder.tableName == "" {
 		return "", define.TableNoneError
 	}
-	column = g.CompileColumns(builder.distinct, builder.column)
+	column = g.compileSelectColumns(builder)
 	from = g.CompileFrom(builder.tableName, builder.tableAlias)
 	join = g.CompileJoin(builder.joins)
-	where = g.CompileWhere(builder.wheres, true)
+	where = g.compileScopedWhere(builder)
 	order = g.CompileOrderBy(builder.orders)
 	offset = g.CompileOffset(builder.limit, builder.offset)
 	g.selectSqlMap["column"] = column
@@ -97,6 +106,20 @@ func (g *Grammar) CompileColumns(distinct bool, columns []string) string {
 	return selectStr + columnStr
 }
 
+func (g *Grammar) compileSelectColumns(builder *Builder) string {
+	if builder.aggregate == "" {
+		return g.CompileColumns(builder.distinct, builder.column)
+	}
+	column := "*"
+	if len(builder.column) > 0 && builder.column[0] != "*" {
+		column = g.syntax.WrapColumn(builder.column[0])
+	}
+	if builder.distinct {
+		column = "distinct " + column
+	}
+	return fmt.Sprintf("select %s(%s) as %s", builder.aggregate, column, g.syntax.WrapColumn("aggregate"))
+}
+
 // compile from table statement
 func (g *Grammar) CompileFrom(table, alias string) string {
 	var fromTable string
@@ -114,8 +137,8 @@ func (g *Grammar) CompileJoin(joins []*BuilderJoin) string {
 	for _, j := range joins {
 		buf.WriteString(g.processJoin(j))
 		buf.WriteString(" ")
-		// merge binding
-		g.binding.AddBinding("where", j.binding.GetBindings())
+		// merge binding, join的参数位于where之前
+		g.binding.AddBinding("join", j.binding.GetBindings())
 	}
 	bufLen := buf.Len()
 	if bufLen <= 0 {
@@ -188,6 +211,10 @@ func (g *Grammar) CompileWhere(wheres []map[string]interface{}, removeLeading bo
 			if str := g.whereBetween(where); str != "" {
 				whereSlice = append(whereSlice, str)
 			}
+		case "Row":
+			if str := g.whereRow(where); str != "" {
+				whereSlice = append(whereSlice, str)
+			}
 		case "Null":
 			if str := g.whereNull(where); str != "" {
 				whereSlice = append(whereSlice, str)
@@ -255,6 +282,18 @@ func (g *Grammar) whereBetween(where map[string]interface{}) string {
 	return fmt.Sprintf("%s %s %s ? and ?", logic, column, typ)
 }
 
+// where row statement
+func (g *Grammar) whereRow(where map[string]interface{}) string {
+	placeholder := g.syntax.ParameterByInterfaceToString(where["value"])
+	if placeholder == "" {
+		return ""
+	}
+	logic := where["logic"].(string)
+	columns := g.syntax.ColumnToString(where["columns"].([]string))
+	operator := where["operator"].(string)
+	return fmt.Sprintf("%s (%s) %s (%s)", logic, columns, operator, placeholder)
+}
+
 // where null statement
 func (g *Grammar) whereNull(where map[string]interface{}) string {
 	var typ string
@@ -285,26 +324,99 @@ func (g *Grammar) whereSub(where map[string]interface{}) string {
 	return fmt.Sprintf("%s %s %s (%s)", logic, column, operator, subSelect)
 }
 
+// 生成带有软删除条件的where语句
+// 用户的条件中有or时需要加括号, 否则软删除条件只对最后一个条件生效
+func (g *Grammar) compileScopedWhere(builder *Builder) string {
+	where := g.CompileWhere(builder.wheres, false)
+	scope := g.CompileSoftDeleteScope(builder)
+	if where != "" {
+		where = removeWhereLeading(where)
+	}
+	switch {
+	case scope == "" && where == "":
+		return ""
+	case scope == "":
+		return "where " + where
+	case where == "":
+		return "where " + scope
+	}
+	for i, w := range builder.wheres {
+		if i > 0 && w["logic"] == "or" {
+			where = "(" + where + ")"
+			break
+		}
+	}
+	return "where " + where + " and " + scope
+}
+
+// 软删除的条件, 没有设置软删除的列或者查询范围包括软删除的数据时返回空
+func (g *Grammar) CompileSoftDeleteScope(builder *Builder) string {
+	if builder.softDelete == "" || builder.trashed == TrashedWith {
+		return ""
+	}
+	column := builder.softDelete
+	// 多表查询时使用表名限定列, 避免列名冲突
+	if len(builder.joins) > 0 && !strings.Contains(column, ".") {
+		table := builder.tableAlias
+		if table == "" {
+			table = builder.tableName
+		}
+		column = table + "." + column
+	}
+	if builder.trashed == TrashedOnly {
+		return g.syntax.WrapColumn(column) + " is not null"
+	}
+	return g.syntax.WrapColumn(column) + " is null"
+}
+
 // compile order statement
+// 多列排序时每一列都带上自己的排序方向, 例如 order by `a` desc,`b` asc
 func (g *Grammar) CompileOrderBy(orders map[string]interface{}) string {
 	column := orders["column"].([]string)
 	if len(column) <= 0 {
 		return ""
 	}
-	direction := orders["direction"]
-	columnStr := g.syntax.ColumnToString(column)
-	return fmt.Sprintf("order by %s %s", columnStr, direction)
+	directions, _ := orders["directions"].([]string)
+	if len(column) == 1 || len(directions) != len(column) {
+		return fmt.Sprintf("order by %s %s", g.syntax.ColumnToString(column), orders["direction"])
+	}
+	var buf bytes.Buffer
+	buf.WriteString("order by ")
+	for i, c := range column {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString(g.syntax.WrapColumn(c))
+		buf.WriteString(" ")
+		buf.WriteString(directions[i])
+	}
+	return buf.String()
 }
 
+// compile limit statement of update and delete,
+// mysql的update和delete不支持offset
+func (g *Grammar) CompileLimit(limit, offset string) (string, error) {
+	if offset != "" {
+		return "", define.UpdateDeleteOffsetError
+	}
+	return limit, nil
+}
+
+// mysql不支持没有limit的offset, 使用文档中的最大值表示不限制行数
+const noLimit = "limit 18446744073709551615"
+
 // compile limit offset statement
+// 只有limit时也需要生成, 否则Take单独使用不会生效,
+// 旧版本在没有Skip时会丢弃limit, 查询返回所有数据
+// 只有offset时使用noLimit
 func (g *Grammar) CompileOffset(limit, offset string) string {
 	if offset == "" {
-		return ""
+		return limit
 	}
-	if limit != "" {
-		return limit + " " + offset
+	if limit == "" {
+		limit = noLimit
 	}
-	return offset
+	return limit + " " + offset
 }
 
 func (g *Grammar) CompileFind(distinct bool, columns []string, table, alias, pk string) string {
@@ -1,17 +1,51 @@
 package query
 
 import (
+	"bytes"
 	"github.com/Soul-Mate/sprydb/define"
-	"fmt"
 )
 
 func (g *Grammar) CompileDelete(builder *Builder) (sqlStr string, err error) {
-	var wheres string
+	var (
+		buf          bytes.Buffer
+		order, limit string
+	)
 	if builder.tableName == "" {
 		err = define.TableNoneError
 		return
 	}
-	wheres = g.CompileWhere(builder.wheres, true)
-	sqlStr = fmt.Sprintf("delete from %s %s", builder.tableName, wheres)
+	order = g.CompileOrderBy(builder.orders)
+	if limit, err = g.CompileLimit(builder.limit, builder.offset); err != nil {
+		return
+	}
+	table := g.syntax.WrapAliasTable(builder.tableName, builder.tableAlias)
+	if len(builder.joins) > 0 {
+		// mysql多表删除不支持order by和limit
+		if order != "" || limit != "" {
+			err = define.MultiTableOrderLimitError
+			return
+		}
+		// 多表删除只删除主表的数据: delete t from t join ...
+		target := builder.tableAlias
+		if target == "" {
+			target = builder.tableName
+		}
+		buf.WriteString("delete ")
+		buf.WriteString(g.syntax.WrapTable(target))
+		buf.WriteString(" from ")
+		buf.WriteString(table)
+		buf.WriteString(" ")
+		buf.WriteString(g.CompileJoin(builder.joins))
+	} else {
+		buf.WriteString("delete from ")
+		buf.WriteString(table)
+	}
+	for _, part := range []string{g.compileScopedWhere(builder), order, limit} {
+		if part != "" {
+			buf.WriteString(" ")
+			buf.WriteString(part)
+		}
+	}
+	sqlStr = buf.String()
 	return
 }
@@ -0,0 +1,61 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Soul-Mate/sprydb/binding"
+	"github.com/Soul-Mate/sprydb/define"
+	"github.com/Soul-Mate/sprydb/syntax"
+)
+
+func TestGrammar_CompileDelete(t *testing.T) {
+	syntax2 := syntax.NewSyntax("mysql")
+	binding2 := binding.NewBinding()
+	grammar2 := NewGrammarFactory("mysql", syntax2, binding2, nil)
+	rawSQL := "delete from `logs` where `created_at` < ? order by `id` asc limit 500"
+	b := NewBuilder("mysql", syntax2, binding2)
+	b.Table("logs").Where("created_at", "<", "2018-01-01").OrderBy("id", "asc").Take(500)
+	sqlStr, err := grammar2.CompileDelete(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sqlStr != rawSQL {
+		t.Errorf("TestGrammar_CompileDelete error: %s", sqlStr)
+	}
+	if !reflect.DeepEqual(binding2.PrepareDeleteBinding(), []interface{}{"2018-01-01"}) {
+		t.Error("TestGrammar_CompileDelete error")
+	}
+
+	b.Skip(10)
+	if _, err = grammar2.CompileDelete(b); err != define.UpdateDeleteOffsetError {
+		t.Error("TestGrammar_CompileDelete error: offset accepted")
+	}
+}
+
+func TestGrammar_CompileDeleteJoin(t *testing.T) {
+	syntax2 := syntax.NewSyntax("mysql")
+	binding2 := binding.NewBinding()
+	grammar2 := NewGrammarFactory("mysql", syntax2, binding2, nil)
+	rawSQL := "delete `p` from `posts` as `p` inner join `users` as `u` on `u`.`id` = `p`.`user_id` " +
+		"and `u`.`status` = ? where `p`.`views` < ?"
+	b := NewBuilder("mysql", syntax2, binding2)
+	b.Table("posts as p").JoinClosure("users as u", func(join *BuilderJoin) {
+		join.On("u.id", "=", "p.user_id").Where("u.status", "=", 0)
+	}).Where("p.views", "<", 10)
+	sqlStr, err := grammar2.CompileDelete(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sqlStr != rawSQL {
+		t.Errorf("TestGrammar_CompileDeleteJoin error: %s", sqlStr)
+	}
+	if !reflect.DeepEqual(binding2.PrepareDeleteBinding(), []interface{}{0, 10}) {
+		t.Errorf("TestGrammar_CompileDeleteJoin error: %v", binding2.PrepareDeleteBinding())
+	}
+
+	b.Take(10)
+	if _, err = grammar2.CompileDelete(b); err != define.MultiTableOrderLimitError {
+		t.Error("TestGrammar_CompileDeleteJoin error: limit with join accepted")
+	}
+}
@@ -58,6 +58,7 @@ func (g *Grammar) processInsertObject(obj interface{}, builder *Builder) (
 	if objMapper, err = mapper.NewMapper(obj, g.syntax, g.styler); err != nil {
 		return
 	}
+	objMapper.SetTimeFormat(g.timeFormat)
 
 	if err = objMapper.Parse(mapper.PARSE_INSERT); err != nil {
 		return
@@ -67,7 +68,7 @@ func (g *Grammar) processInsertObject(obj interface{}, builder *Builder) (
 		builder.tableName = objMapper.GetTable()
 	}
 
-	if columns, values = objMapper.GetInsertColumnAndValues(); len(columns) <= 0 {
+	if columns, values, err = objMapper.GetInsertColumnAndValues(); err != nil || len(columns) <= 0 {
 		return
 	}
 	table = g.syntax.WrapTable(builder.tableName)
@@ -0,0 +1,39 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/Soul-Mate/sprydb/binding"
+	"github.com/Soul-Mate/sprydb/syntax"
+)
+
+func TestGrammar_CompileSelectLimit(t *testing.T) {
+	syntax2 := syntax.NewSyntax("mysql")
+	cases := []struct {
+		build  func(b *Builder)
+		rawSQL string
+	}{
+		{func(b *Builder) {}, "select * from `users`"},
+		// 只使用Take时也需要生成limit
+		{func(b *Builder) { b.Take(10) }, "select * from `users` limit 10"},
+		{func(b *Builder) { b.Skip(20).Take(10) }, "select * from `users` limit 10 offset 20"},
+		// mysql不支持单独的offset
+		{func(b *Builder) { b.Skip(20) }, "select * from `users` limit 18446744073709551615 offset 20"},
+		{func(b *Builder) { b.Where("id", ">", 1).OrderBy("id", "asc").Take(5) },
+			"select * from `users` where `id` > ? order by `id` asc limit 5"},
+	}
+	for _, c := range cases {
+		binding2 := binding.NewBinding()
+		grammar2 := NewGrammarFactory("mysql", syntax2, binding2, nil)
+		b := NewBuilder("mysql", syntax2, binding2)
+		b.Table("users")
+		c.build(b)
+		sqlStr, err := grammar2.CompileSelect(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sqlStr != c.rawSQL {
+			t.Errorf("TestGrammar_CompileSelectLimit error: %s", sqlStr)
+		}
+	}
+}
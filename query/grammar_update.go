@@ -5,14 +5,15 @@ import (
 	"github.com/Soul-Mate/sprydb/define"
 	"fmt"
 	"bytes"
+	"sort"
 	"github.com/Soul-Mate/sprydb/mapper"
 )
 
 func (g *Grammar) CompileUpdate(value interface{}, builder *Builder) (string, []interface{}, error) {
 	var (
-		err                           error
-		bindings                      []interface{}
-		table, columns, wheres, joins string
+		err            error
+		bindings       []interface{}
+		table, columns string
 	)
 	v := reflect.ValueOf(value)
 	t := v.Type()
@@ -35,12 +36,85 @@ func (g *Grammar) CompileUpdate(value interface{}, builder *Builder) (string, []
 	if columns == "" {
 		return "", nil, nil
 	}
-	wheres = g.CompileWhere(builder.wheres, true)
-	joins = g.CompileJoin(builder.joins)
-	sqlStr := fmt.Sprintf("update %s %s set %s %s", table, joins, columns, wheres)
+	if sqlStr, err := g.buildUpdateSql(table, columns, builder); err != nil {
+		return "", nil, err
+	} else {
+		return sqlStr, bindings, nil
+	}
+}
+
+// 自增/自减更新, 生成 set col = col + ? 语句
+// extra 中的列会在同一条语句中一起更新
+func (g *Grammar) CompileIncrement(column, operator string, amount interface{}, extra map[string]interface{}, builder *Builder) (
+	string, []interface{}, error) {
+	var (
+		keys     []string
+		bindings []interface{}
+		buf      bytes.Buffer
+	)
+	if builder.tableName == "" {
+		return "", nil, define.TableNoneError
+	}
+
+	if operator != "+" && operator != "-" {
+		return "", nil, define.InvalidOperatorError
+	}
+
+	wrap := g.syntax.WrapColumn(column)
+	buf.WriteString(fmt.Sprintf("%s = %s %s ?", wrap, wrap, operator))
+	bindings = append(bindings, amount)
+
+	// 对额外更新的列排序, 保证生成的sql稳定
+	for k := range extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf.WriteString(",")
+		buf.WriteString(g.syntax.WrapColumn(k))
+		buf.WriteString(" = ?")
+		bindings = append(bindings, extra[k])
+	}
+
+	table := g.syntax.WrapAliasTable(builder.tableName, builder.tableAlias)
+	sqlStr, err := g.buildUpdateSql(table, buf.String(), builder)
+	if err != nil {
+		return "", nil, err
+	}
 	return sqlStr, bindings, nil
 }
 
+// 生成update语句, 拼接join, where, order by和limit
+// mysql多表update不支持order by和limit
+// 为空的部分不输出, 旧版本没有join和where时会生成多余的空格,
+// 例如 "update `users`  set `name` = ? ", 现在生成 "update `users` set `name` = ?"
+func (g *Grammar) buildUpdateSql(table, columns string, builder *Builder) (string, error) {
+	var buf bytes.Buffer
+	order := g.CompileOrderBy(builder.orders)
+	limit, err := g.CompileLimit(builder.limit, builder.offset)
+	if err != nil {
+		return "", err
+	}
+	if len(builder.joins) > 0 && (order != "" || limit != "") {
+		return "", define.MultiTableOrderLimitError
+	}
+	buf.WriteString("update ")
+	buf.WriteString(table)
+	if joins := g.CompileJoin(builder.joins); joins != "" {
+		buf.WriteString(" ")
+		buf.WriteString(joins)
+	}
+	buf.WriteString(" set ")
+	buf.WriteString(columns)
+	for _, part := range []string{g.compileScopedWhere(builder), order, limit} {
+		if part != "" {
+			buf.WriteString(" ")
+			buf.WriteString(part)
+		}
+	}
+	return buf.String(), nil
+}
+
 func (g *Grammar) processUpdateMapType(pointer bool, value interface{}, builder *Builder) (
 	table, column string, bindings []interface{}, err error) {
 	var (
@@ -95,6 +169,7 @@ func (g *Grammar) processUpdateObjectType(value interface{}, builder *Builder) (
 	if objMapper, err = mapper.NewMapper(value, g.syntax, g.styler); err != nil {
 		return
 	}
+	objMapper.SetTimeFormat(g.timeFormat)
 
 	if builder.tableName != "" {
 		objMapper.SetTable(builder.tableName)
@@ -102,6 +177,7 @@ func (g *Grammar) processUpdateObjectType(value interface{}, builder *Builder) (
 	}
 
 	objMapper.SetJoinMap(&builder.joinMap)
+	objMapper.SetUpdateColumns(builder.updateColumns)
 	if err = objMapper.Parse(mapper.PARSE_UPDATE); err != nil {
 		return
 	}
@@ -111,13 +187,37 @@ func (g *Grammar) processUpdateObjectType(value interface{}, builder *Builder) (
 		builder.tableAlias = objMapper.GetAlias()
 	}
 
-	if columns, values = objMapper.GetUpdateColumnAndValues(); len(columns) <= 0 {
+	if columns, values, err = objMapper.GetUpdateColumnAndValues(); err != nil {
+		return
+	}
+	version := objMapper.GetVersionColumn()
+	if version != "" {
+		columns, values = removeUpdateColumn(columns, values, version)
+	}
+	if len(columns) <= 0 && version == "" {
 		err = define.UpdateEmptyStructError
 		return
 	}
 
 	table = g.syntax.WrapAliasTable(builder.tableName, builder.tableAlias)
 	column = g.syntax.ColumnToUpdateString(columns)
+	// 版本号列总是在数据库中自增
+	if version != "" {
+		wrap := g.syntax.WrapColumn(version)
+		if column != "" {
+			column += ","
+		}
+		column += fmt.Sprintf("%s = %s + 1", wrap, wrap)
+	}
 	bindings = append(bindings, values...)
 	return
 }
+
+func removeUpdateColumn(columns []string, values []interface{}, column string) ([]string, []interface{}) {
+	for i, c := range columns {
+		if c == column {
+			return append(columns[:i:i], columns[i+1:]...), append(values[:i:i], values[i+1:]...)
+		}
+	}
+	return columns, values
+}
@@ -0,0 +1,120 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Soul-Mate/sprydb/binding"
+	"github.com/Soul-Mate/sprydb/syntax"
+)
+
+func TestGrammar_CompileIncrement(t *testing.T) {
+	syntax2 := syntax.NewSyntax("mysql")
+	binding2 := binding.NewBinding()
+	grammar2 := NewGrammarFactory("mysql", syntax2, binding2, nil)
+	rawSQL := "update `goods` set `stock` = `stock` - ?,`sold` = ?,`status` = ? where `id` = ?"
+	b := NewBuilder("mysql", syntax2, binding2)
+	b.Table("goods").Where("id", "=", 1)
+	sqlStr, bindings, err := grammar2.CompileIncrement("stock", "-", 2, map[string]interface{}{
+		"status": 1,
+		"sold":   10,
+	}, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sqlStr != rawSQL {
+		t.Errorf("TestGrammar_CompileIncrement error: %s", sqlStr)
+	}
+	bindings = binding2.PrepareUpdateBinding(bindings)
+	if !reflect.DeepEqual(bindings, []interface{}{2, 10, 1, 1}) {
+		t.Errorf("TestGrammar_CompileIncrement error: %v", bindings)
+	}
+}
+
+func TestGrammar_CompileIncrementJoin(t *testing.T) {
+	syntax2 := syntax.NewSyntax("mysql")
+	binding2 := binding.NewBinding()
+	grammar2 := NewGrammarFactory("mysql", syntax2, binding2, nil)
+	rawSQL := "update `posts` as `p` inner join `users` as `u` on `u`.`id` = `p`.`user_id` " +
+		"set `p`.`views` = `p`.`views` + ? where `u`.`id` = ?"
+	b := NewBuilder("mysql", syntax2, binding2)
+	b.Table("posts as p").Join("users as u", "u.id", "=", "p.user_id").Where("u.id", "=", 3)
+	sqlStr, _, err := grammar2.CompileIncrement("p.views", "+", 1, nil, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sqlStr != rawSQL {
+		t.Errorf("TestGrammar_CompileIncrementJoin error: %s", sqlStr)
+	}
+
+	if _, _, err = grammar2.CompileIncrement("p.views", "*", 1, nil, b); err == nil {
+		t.Error("TestGrammar_CompileIncrementJoin error: invalid operator accepted")
+	}
+}
+
+func TestGrammar_CompileUpdateOrderLimit(t *testing.T) {
+	syntax2 := syntax.NewSyntax("mysql")
+	binding2 := binding.NewBinding()
+	grammar2 := NewGrammarFactory("mysql", syntax2, binding2, nil)
+	rawSQL := "update `jobs` set `status` = ? where `status` = ? order by `id` asc limit 100"
+	b := NewBuilder("mysql", syntax2, binding2)
+	b.Table("jobs").Where("status", "=", 0).OrderBy("id", "asc").Take(100)
+	sqlStr, bindings, err := grammar2.CompileUpdate(map[string]interface{}{"status": 1}, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sqlStr != rawSQL {
+		t.Errorf("TestGrammar_CompileUpdateOrderLimit error: %s", sqlStr)
+	}
+	bindings = binding2.PrepareUpdateBinding(bindings)
+	if !reflect.DeepEqual(bindings, []interface{}{1, 0}) {
+		t.Errorf("TestGrammar_CompileUpdateOrderLimit error: %v", bindings)
+	}
+}
+
+func TestGrammar_CompileUpdateJoinBinding(t *testing.T) {
+	syntax2 := syntax.NewSyntax("mysql")
+	binding2 := binding.NewBinding()
+	grammar2 := NewGrammarFactory("mysql", syntax2, binding2, nil)
+	rawSQL := "update `posts` as `p` inner join `users` as `u` on `u`.`id` = `p`.`user_id` " +
+		"and `u`.`status` = ? set `p`.`hidden` = ? where `p`.`views` < ?"
+	b := NewBuilder("mysql", syntax2, binding2)
+	b.Table("posts as p").JoinClosure("users as u", func(join *BuilderJoin) {
+		join.On("u.id", "=", "p.user_id").Where("u.status", "=", 0)
+	}).Where("p.views", "<", 10)
+	sqlStr, bindings, err := grammar2.CompileUpdate(map[string]interface{}{"p.hidden": 1}, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sqlStr != rawSQL {
+		t.Errorf("TestGrammar_CompileUpdateJoinBinding error: %s", sqlStr)
+	}
+	bindings = binding2.PrepareUpdateBinding(bindings)
+	if !reflect.DeepEqual(bindings, []interface{}{0, 1, 10}) {
+		t.Errorf("TestGrammar_CompileUpdateJoinBinding error: %v", bindings)
+	}
+}
+
+func TestGrammar_CompileUpdateSpacing(t *testing.T) {
+	syntax2 := syntax.NewSyntax("mysql")
+	binding2 := binding.NewBinding()
+	grammar2 := NewGrammarFactory("mysql", syntax2, binding2, nil)
+	b := NewBuilder("mysql", syntax2, binding2)
+	b.Table("users")
+	sqlStr, _, err := grammar2.CompileUpdate(map[string]interface{}{"name": "foo"}, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sqlStr != "update `users` set `name` = ?" {
+		t.Errorf("TestGrammar_CompileUpdateSpacing error: %q", sqlStr)
+	}
+
+	b.Where("id", "=", 1)
+	sqlStr, _, err = grammar2.CompileUpdate(map[string]interface{}{"name": "foo"}, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sqlStr != "update `users` set `name` = ? where `id` = ?" {
+		t.Errorf("TestGrammar_CompileUpdateSpacing error: %q", sqlStr)
+	}
+}
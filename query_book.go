@@ -0,0 +1,142 @@
+package sprydb
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Soul-Mate/sprydb/define"
+)
+
+// sql文件中查询名称的注释前缀, 例如 -- name: GetActiveUsers
+const queryNamePrefix = "name:"
+
+// 从.sql文件加载的命名查询
+type QueryBook struct {
+	queries map[string]string
+}
+
+// 加载目录下所有的.sql文件, 包括子目录
+func LoadQueryBook(dir string) (*QueryBook, error) {
+	return LoadQueryBookFS(os.DirFS(dir))
+}
+
+// 加载文件系统中所有的.sql文件, 可以使用embed.FS
+func LoadQueryBookFS(fsys fs.FS) (*QueryBook, error) {
+	book := &QueryBook{queries: make(map[string]string)}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(name) != ".sql" {
+			return nil
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if err = book.Parse(string(content)); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return book, nil
+}
+
+// 解析sql文件的内容, 每个查询以 -- name: 注释开始, 到下一个name注释或者文件结束
+// 第一个name注释之前的内容会被忽略
+func (b *QueryBook) Parse(content string) error {
+	var (
+		name string
+		buf  strings.Builder
+	)
+	if b.queries == nil {
+		b.queries = make(map[string]string)
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if next, ok := parseQueryName(line); ok {
+			if err := b.add(name, buf.String()); err != nil {
+				return err
+			}
+			name = next
+			buf.Reset()
+			continue
+		}
+		if name != "" {
+			buf.WriteString(line)
+			buf.WriteString("\n")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return b.add(name, buf.String())
+}
+
+func (b *QueryBook) add(name, query string) error {
+	if name == "" {
+		return nil
+	}
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+	if query == "" {
+		return fmt.Errorf("%w: %s", define.EmptyNamedQueryError, name)
+	}
+	if _, ok := b.queries[name]; ok {
+		return fmt.Errorf("%w: %s", define.DuplicateNamedQueryError, name)
+	}
+	b.queries[name] = query
+	return nil
+}
+
+// 解析 -- name: xxx 注释
+func parseQueryName(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "--") {
+		return "", false
+	}
+	line = strings.TrimSpace(strings.TrimPrefix(line, "--"))
+	if !strings.HasPrefix(line, queryNamePrefix) {
+		return "", false
+	}
+	name := strings.TrimSpace(strings.TrimPrefix(line, queryNamePrefix))
+	return name, name != ""
+}
+
+// 根据名称获取查询
+func (b *QueryBook) Get(name string) (string, bool) {
+	query, ok := b.queries[name]
+	return query, ok
+}
+
+// 所有查询的名称, 按字母排序
+func (b *QueryBook) Names() []string {
+	names := make([]string, 0, len(b.queries))
+	for name := range b.queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 执行查询本中的命名查询, 参数与Raw相同
+func (s *Session) Named(name string, args ...interface{}) *RawQuery {
+	if s.connection.queryBook == nil {
+		return &RawQuery{err: fmt.Errorf("%w: %s", define.NamedQueryNotFoundError, name), session: s}
+	}
+	query, ok := s.connection.queryBook.Get(name)
+	if !ok {
+		return &RawQuery{err: fmt.Errorf("%w: %s", define.NamedQueryNotFoundError, name), session: s}
+	}
+	return s.Raw(query, args...)
+}
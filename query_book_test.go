@@ -0,0 +1,67 @@
+package sprydb
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Soul-Mate/sprydb/define"
+)
+
+func TestLoadQueryBook(t *testing.T) {
+	book, err := LoadQueryBook("testdata/queries")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := book.Names(); len(names) != 2 || names[0] != "DeactivateUser" {
+		t.Errorf("Names error: %v", names)
+	}
+	query, _ := book.Get("GetActiveUsers")
+	if want := "select id, name\nfrom users\nwhere status = :status\norder by id"; query != want {
+		t.Errorf("GetActiveUsers error: %q", query)
+	}
+
+	_, err = LoadQueryBookFS(fstest.MapFS{
+		"a.sql": {Data: []byte("-- name: A\nselect 1")},
+		"b.sql": {Data: []byte("-- name: A\nselect 2")},
+	})
+	if !errors.Is(err, define.DuplicateNamedQueryError) {
+		t.Errorf("duplicate query should fail, got %v", err)
+	}
+
+	_, err = LoadQueryBookFS(fstest.MapFS{
+		"a.sql": {Data: []byte("-- name: A\n-- name: B\nselect 2")},
+	})
+	if !errors.Is(err, define.EmptyNamedQueryError) {
+		t.Errorf("empty query should fail, got %v", err)
+	}
+}
+
+func TestConnection_Named(t *testing.T) {
+	columns, rows := fakeUserRows()
+	conn, d := newFakeConnection(t, columns, rows)
+	book, err := LoadQueryBook("testdata/queries")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetQueryBook(book)
+
+	var users []fakeUser
+	if err = conn.Named("GetActiveUsers", map[string]interface{}{"status": 1}).Get(&users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 3 || d.args[0][0] != int64(1) {
+		t.Errorf("Named Get error: %v %v", users, d.args[0])
+	}
+
+	if _, err = conn.Named("DeactivateUser", 2).Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if d.execs[0] != "update users set status = 0 where id = ?" || d.args[1][0] != int64(2) {
+		t.Errorf("Named Exec error: %v %v", d.execs, d.args[1])
+	}
+
+	if err = conn.Named("Missing").Get(&users); !errors.Is(err, define.NamedQueryNotFoundError) {
+		t.Errorf("missing query should fail, got %v", err)
+	}
+}
@@ -0,0 +1,306 @@
+package sprydb
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Soul-Mate/sprydb/define"
+	"github.com/Soul-Mate/sprydb/mapper"
+)
+
+// 手写sql的查询, 通过Scan将结果映射到struct, slice或者map
+type RawQuery struct {
+	err     error
+	sql     string
+	args    []interface{}
+	session *Session
+}
+
+// 创建手写sql的查询
+// 只有一个参数并且是map[string]interface{}或者struct时, 使用参数中的值替换sql中的命名参数(:name),
+// struct使用映射的列名作为参数名, slice类型的值会展开为多个占位符, 可以用于in查询
+func (s *Session) Raw(sqlStr string, args ...interface{}) *RawQuery {
+	raw := &RawQuery{sql: sqlStr, args: args, session: s}
+	if len(args) == 1 && isNamedArg(args[0]) {
+		raw.sql, raw.args, raw.err = s.compileNamed(sqlStr, args[0])
+	}
+	return raw
+}
+
+// 执行查询并扫描结果, dest可以是:
+// *struct, *[]struct, *[]*struct, *map[string]interface{}, *[]map[string]interface{}
+// dest是单个struct或map时只扫描第一行, 没有数据时返回ErrRecordNotFound
+func (r *RawQuery) Scan(dest interface{}) error {
+	var (
+		err  error
+		stmt *sql.Stmt
+		rows *sql.Rows
+	)
+
+	if r.err != nil {
+		return r.err
+	}
+
+	if dest == nil {
+		return define.ObjectNoneError
+	}
+
+	t := reflect.TypeOf(dest)
+	if t.Kind() != reflect.Ptr {
+		return define.UnsupportedTypeError
+	}
+
+	s := r.session
+	if s.connection.logging != nil {
+		defer s.connection.logging.Append(r.sql, r.args...)
+	}
+
+	if stmt, err = s.prepare(r.sql); err != nil {
+		return err
+	}
+
+	if rows, err = s.query(stmt, r.args...); err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	switch dest := dest.(type) {
+	case *map[string]interface{}:
+		result, err := s.scanFirstMap(rows)
+		if err != nil {
+			return err
+		}
+		*dest = result
+		return nil
+	case *[]map[string]interface{}:
+		columns, err := rows.ColumnTypes()
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			result, err := scanMap(rows, columns, s.connection.rawMapValues, s.connection.timeFormat)
+			if err != nil {
+				return err
+			}
+			*dest = append(*dest, result)
+		}
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		return rows.Close()
+	}
+
+	if t.Elem().Kind() == reflect.Struct {
+		return r.scanStructs(rows, t.Elem(), func(obj reflect.Value) bool {
+			reflect.ValueOf(dest).Elem().Set(obj.Elem())
+			return false
+		})
+	}
+
+	if t.Elem().Kind() == reflect.Slice {
+		structType, ptrElem := structElem(t.Elem().Elem())
+		if structType == nil {
+			return define.UnsupportedTypeError
+		}
+		elem := reflect.ValueOf(dest).Elem()
+		err = r.scanStructs(rows, structType, func(obj reflect.Value) bool {
+			elem.Set(reflect.Append(elem, copyStruct(obj, ptrElem)))
+			return true
+		})
+		if err == define.ErrRecordNotFound || err == nil {
+			return rows.Close()
+		}
+		return err
+	}
+
+	return define.UnsupportedTypeError
+}
+
+// 与Scan相同
+func (r *RawQuery) Get(dest interface{}) error {
+	return r.Scan(dest)
+}
+
+// 执行不返回结果集的sql, 例如insert, update, delete
+func (r *RawQuery) Exec() (sql.Result, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.session.connection.logging != nil {
+		defer r.session.connection.logging.Append(r.sql, r.args...)
+	}
+	return r.session.Exec(r.sql, r.args...)
+}
+
+// 使用映射器按照结果集的列名扫描每一行, 没有映射的列会被忽略
+// f返回false时停止扫描, 没有数据时返回notFound
+func (r *RawQuery) scanStructs(rows *sql.Rows, structType reflect.Type, f func(obj reflect.Value) bool) error {
+	obj := reflect.New(structType)
+	objMapper, err := r.session.newMapper(obj.Interface())
+	if err != nil {
+		return err
+	}
+	if err = objMapper.Parse(mapper.PARSE_SELECT); err != nil {
+		return err
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	address := make([]interface{}, len(columns))
+	for i, c := range columns {
+		if addr := objMapper.GetAddressByColumn([]string{c}); len(addr) > 0 {
+			address[i] = addr[0]
+		} else {
+			address[i] = new(sql.RawBytes)
+		}
+	}
+
+	found := false
+	child := r.session.childSession()
+	for rows.Next() {
+		found = true
+		if err = rows.Scan(address...); err != nil {
+			return err
+		}
+		if err = objMapper.AssignAddressValue(); err != nil {
+			return err
+		}
+		if err = callHookValue(obj, mapper.AfterFind, child); err != nil {
+			return err
+		}
+		if !f(obj) {
+			break
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	if !found {
+		return r.session.notFound()
+	}
+	return rows.Close()
+}
+
+// 是否可以作为命名参数, time.Time和driver.Valuer等作为一个值的struct是普通参数
+func isNamedArg(arg interface{}) bool {
+	if _, ok := arg.(map[string]interface{}); ok {
+		return true
+	}
+	t := reflect.TypeOf(arg)
+	if t == nil {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return mapper.IsExtendStruct(t)
+}
+
+// 将sql中的命名参数替换为占位符, 返回替换后的sql和按顺序排列的参数
+// 引号中的内容不会被替换
+func (s *Session) compileNamed(sqlStr string, arg interface{}) (string, []interface{}, error) {
+	var (
+		buf   strings.Builder
+		args  []interface{}
+		quote byte
+	)
+
+	lookup, err := s.namedLookup(arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for i := 0; i < len(sqlStr); i++ {
+		c := sqlStr[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			buf.WriteByte(c)
+			continue
+		}
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == ':' && i+1 < len(sqlStr) && sqlStr[i+1] == ':':
+			buf.WriteString("::")
+			i++
+			continue
+		case c == ':' && i+1 < len(sqlStr) && isNameStart(sqlStr[i+1]):
+			j := i + 1
+			for j < len(sqlStr) && isNamePart(sqlStr[j]) {
+				j++
+			}
+			name := sqlStr[i+1 : j]
+			value, ok := lookup(name)
+			if !ok {
+				return "", nil, fmt.Errorf("%w: %s", define.NamedParameterError, name)
+			}
+			values := expandNamedValue(value)
+			buf.WriteString(strings.TrimSuffix(strings.Repeat("?,", len(values)), ","))
+			args = append(args, values...)
+			i = j - 1
+			continue
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String(), args, nil
+}
+
+// 根据参数名获取值的函数, struct使用映射的列名
+func (s *Session) namedLookup(arg interface{}) (func(name string) (interface{}, bool), error) {
+	if m, ok := arg.(map[string]interface{}); ok {
+		return func(name string) (interface{}, bool) {
+			v, ok := m[name]
+			return v, ok
+		}, nil
+	}
+
+	v := reflect.ValueOf(arg)
+	if v.Kind() != reflect.Ptr {
+		// 映射器需要可以取地址的struct
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		v = p
+	}
+	objMapper, err := s.newMapper(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+	// 只读取参数的值, PARSE_SELECT会为空的连接查询struct指针分配对象
+	if err = objMapper.Parse(mapper.PARSE_UPDATE); err != nil {
+		return nil, err
+	}
+	return func(name string) (interface{}, bool) {
+		if value, ok := objMapper.GetValueByColumn(name); ok {
+			return value, true
+		}
+		// 空指针字段作为NULL
+		return nil, objMapper.HasColumn(name)
+	}, nil
+}
+
+// slice类型的值展开为多个参数, []byte除外
+func expandNamedValue(value interface{}) []interface{} {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return []interface{}{value}
+	}
+	values := make([]interface{}, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isNamePart(c byte) bool {
+	return isNameStart(c) || c >= '0' && c <= '9'
+}
@@ -0,0 +1,106 @@
+package sprydb
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/Soul-Mate/sprydb/define"
+)
+
+func TestSession_Raw(t *testing.T) {
+	conn, d := newFakeConnection(t, []string{"id", "total", "name"}, [][]driver.Value{
+		{int64(1), int64(10), []byte("foo")},
+		{int64(2), int64(20), []byte("bar")},
+	})
+
+	var users []*fakeUser
+	if err := conn.Raw("select id, sum(x) as total, name from users where id > ?", 0).Scan(&users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[1].Id != 2 || users[1].Name != "bar" {
+		t.Errorf("Raw slice error: %v", users)
+	}
+
+	user := fakeUser{}
+	if err := conn.Raw("select 1").Scan(&user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Id != 1 || user.Name != "foo" {
+		t.Errorf("Raw struct error: %v", user)
+	}
+
+	var results []map[string]interface{}
+	if err := conn.Raw("select 1").Scan(&results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0]["total"] != int64(10) {
+		t.Errorf("Raw maps error: %v", results)
+	}
+	assertNoConnectionInUse(t, conn, "Raw")
+
+	d.rows = nil
+	if err := conn.Raw("select 1").Scan(&user); err != define.ErrRecordNotFound {
+		t.Errorf("Raw struct should return ErrRecordNotFound, got %v", err)
+	}
+	users = nil
+	if err := conn.Raw("select 1").Scan(&users); err != nil || len(users) != 0 {
+		t.Errorf("Raw slice should be empty, got %v %v", users, err)
+	}
+}
+
+func TestSession_RawNamed(t *testing.T) {
+	conn, d := newFakeConnection(t, []string{"id"}, [][]driver.Value{{int64(1)}})
+
+	var user fakeUser
+	err := conn.Raw("select * from users where id in (:ids) and name = :name and note = ':skip' and a::text = 'x'",
+		map[string]interface{}{"ids": []int{1, 2}, "name": "foo"}).Scan(&user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "select * from users where id in (?,?) and name = ? and note = ':skip' and a::text = 'x'"
+	if d.queries[0] != want {
+		t.Errorf("named sql error: %s", d.queries[0])
+	}
+	if len(d.args[0]) != 3 || d.args[0][1] != int64(2) || d.args[0][2] != "foo" {
+		t.Errorf("named args error: %v", d.args[0])
+	}
+
+	if err = conn.Raw("select * from users where name = :name", fakeUser{Name: "bar"}).Scan(&user); err != nil {
+		t.Fatal(err)
+	}
+	if d.queries[1] != "select * from users where name = ?" || d.args[1][0] != "bar" {
+		t.Errorf("named struct error: %s %v", d.queries[1], d.args[1])
+	}
+
+	err = conn.Raw("select :missing", map[string]interface{}{}).Scan(&user)
+	if !errors.Is(err, define.NamedParameterError) {
+		t.Errorf("missing named parameter should fail, got %v", err)
+	}
+}
+
+type rawNamedProfile struct {
+	City string `spry:"col:city"`
+}
+
+type rawNamedArg struct {
+	Name    string           `spry:"col:name"`
+	Score   *int             `spry:"col:score"`
+	Profile *rawNamedProfile `spry:"col:profile"`
+}
+
+func TestSession_RawNamedStructReadOnly(t *testing.T) {
+	conn, d := newFakeConnection(t, []string{"id"}, [][]driver.Value{{int64(1)}})
+
+	arg := rawNamedArg{Name: "foo"}
+	var user fakeUser
+	if err := conn.Raw("select * from users where name = :name and score = :score", &arg).Scan(&user); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.args[0]) != 2 || d.args[0][0] != "foo" || d.args[0][1] != nil {
+		t.Errorf("named args error: %v", d.args[0])
+	}
+	if arg.Profile != nil || arg.Score != nil {
+		t.Errorf("named struct should not be modified: %+v", arg)
+	}
+}
@@ -0,0 +1,325 @@
+package sprydb
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Soul-Mate/sprydb/define"
+	"github.com/Soul-Mate/sprydb/mapper"
+)
+
+// 预加载的关联关系树, key是字段名
+type relationTree map[string]relationTree
+
+func newRelationTree(paths []string) relationTree {
+	tree := make(relationTree)
+	for _, path := range paths {
+		node := tree
+		for _, name := range strings.Split(path, ".") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if node[name] == nil {
+				node[name] = make(relationTree)
+			}
+			node = node[name]
+		}
+	}
+	return tree
+}
+
+// 为查询结果预加载关联关系, value是struct或者struct(指针)的slice
+// 每个关联关系只执行一次 where key in (...) 查询, many_to_many需要额外查询一次中间表
+func (s *Session) eagerLoad(value reflect.Value, paths []string) error {
+	var parents []reflect.Value
+	switch value.Kind() {
+	case reflect.Struct:
+		parents = append(parents, value)
+	case reflect.Slice:
+		for i, n := 0, value.Len(); i < n; i++ {
+			item := value.Index(i)
+			if item.Kind() == reflect.Ptr {
+				if item.IsNil() {
+					continue
+				}
+				item = item.Elem()
+			}
+			parents = append(parents, item)
+		}
+	default:
+		return define.UnsupportedTypeError
+	}
+
+	structType := value.Type()
+	if value.Kind() == reflect.Slice {
+		structType = value.Type().Elem()
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+	}
+	return s.loadRelations(structType, parents, newRelationTree(paths))
+}
+
+func (s *Session) loadRelations(structType reflect.Type, parents []reflect.Value, tree relationTree) error {
+	// 按字段名排序, 保证查询顺序稳定
+	names := make([]string, 0, len(tree))
+	for name := range tree {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f, ok := structType.FieldByName(name)
+		if !ok {
+			return fmt.Errorf("%w: %s.%s", define.RelationNotFoundError, structType.Name(), name)
+		}
+		rel, ok := mapper.ParseRelation(structType, f, s.connection.style)
+		if !ok {
+			return fmt.Errorf("%w: %s.%s", define.RelationNotFoundError, structType.Name(), name)
+		}
+		if err := s.loadRelation(rel, parents, tree[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Session) loadRelation(rel *mapper.Relation, parents []reflect.Value, sub relationTree) error {
+	var (
+		err        error
+		parentKey  string // 父对象中用于匹配的列
+		relatedKey string // 关联对象中用于匹配的列
+		items      []reflect.Value
+		pivot      map[string][]string // many_to_many父对象的key -> 关联对象的key
+	)
+
+	switch rel.Kind {
+	case mapper.HasOne, mapper.HasMany:
+		parentKey, relatedKey = rel.LocalKey, rel.ForeignKey
+	case mapper.BelongsTo:
+		parentKey, relatedKey = rel.ForeignKey, rel.OwnerKey
+	case mapper.ManyToMany:
+		parentKey, relatedKey = rel.LocalKey, rel.OwnerKey
+	}
+
+	keys, err := s.relationKeys(parents, parentKey)
+	if err != nil {
+		return err
+	}
+
+	queryKeys := keys
+	if rel.Kind == mapper.ManyToMany {
+		if pivot, queryKeys, err = s.queryPivot(rel, keys); err != nil {
+			return err
+		}
+	}
+
+	if items, err = s.queryRelated(rel, relatedKey, queryKeys); err != nil {
+		return err
+	}
+
+	// 先加载嵌套的关联关系, 再将关联对象复制到父对象
+	if len(sub) > 0 && len(items) > 0 {
+		if err = s.loadRelations(rel.Type, items, sub); err != nil {
+			return err
+		}
+	}
+
+	grouped := make(map[string][]reflect.Value)
+	for _, item := range items {
+		key, err := s.relationKey(item, relatedKey)
+		if err != nil {
+			return err
+		}
+		grouped[key] = append(grouped[key], item)
+	}
+
+	for _, parent := range parents {
+		key, err := s.relationKey(parent, parentKey)
+		if err != nil {
+			return err
+		}
+		related := grouped[key]
+		if rel.Kind == mapper.ManyToMany {
+			related = nil
+			for _, k := range pivot[key] {
+				related = append(related, grouped[k]...)
+			}
+		}
+		setRelation(parent.FieldByName(rel.Field), rel, related)
+	}
+	return nil
+}
+
+// 收集父对象中column列的值, 去除重复和空值
+func (s *Session) relationKeys(parents []reflect.Value, column string) ([]interface{}, error) {
+	var keys []interface{}
+	seen := make(map[string]bool)
+	for _, parent := range parents {
+		value, ok, err := s.columnValue(parent, column)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if key := fmt.Sprint(value); !seen[key] {
+			seen[key] = true
+			keys = append(keys, value)
+		}
+	}
+	return keys, nil
+}
+
+// 查询many_to_many的中间表, 返回父对象的key到关联对象key的映射和所有关联对象的key
+func (s *Session) queryPivot(rel *mapper.Relation, keys []interface{}) (map[string][]string, []interface{}, error) {
+	pivot := make(map[string][]string)
+	if len(keys) <= 0 {
+		return pivot, nil, nil
+	}
+
+	child := s.childSession()
+	defer child.Close()
+	child.Table(rel.JoinTable).Select(rel.ForeignKey, rel.AssociationKey)
+	child.queryBuilder.WhereIn(rel.ForeignKey, keys...)
+	rows, err := child.GetReturnMap()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var related []interface{}
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		owner, target := fmt.Sprint(row[rel.ForeignKey]), fmt.Sprint(row[rel.AssociationKey])
+		pivot[owner] = append(pivot[owner], target)
+		if !seen[target] {
+			seen[target] = true
+			related = append(related, row[rel.AssociationKey])
+		}
+	}
+	return pivot, related, nil
+}
+
+// 查询column列的值在keys中的关联对象, 返回的对象可以取地址
+func (s *Session) queryRelated(rel *mapper.Relation, column string, keys []interface{}) ([]reflect.Value, error) {
+	if len(keys) <= 0 {
+		return nil, nil
+	}
+
+	child := s.childSession()
+	defer child.Close()
+	if rel.Table != "" {
+		child.Table(rel.Table)
+	}
+	child.queryBuilder.WhereIn(column, keys...)
+
+	slice := reflect.New(reflect.SliceOf(rel.Type))
+	if err := child.Get(slice.Interface()); err != nil {
+		return nil, err
+	}
+
+	elem := slice.Elem()
+	items := make([]reflect.Value, elem.Len())
+	for i := range items {
+		items[i] = elem.Index(i)
+	}
+	return items, nil
+}
+
+// 加载关联关系和调用钩子使用的session, 与当前session使用同一个事务
+func (s *Session) childSession() *Session {
+	child := NewSession(s.connection)
+	child.ctx = s.ctx
+	child.transaction = s.transaction
+	return child
+}
+
+// 将关联对象设置到字段
+func setRelation(field reflect.Value, rel *mapper.Relation, items []reflect.Value) {
+	if rel.Slice {
+		slice := reflect.MakeSlice(field.Type(), 0, len(items))
+		for _, item := range items {
+			if rel.Ptr {
+				slice = reflect.Append(slice, item.Addr())
+			} else {
+				slice = reflect.Append(slice, item)
+			}
+		}
+		field.Set(slice)
+		return
+	}
+	if len(items) <= 0 {
+		field.Set(reflect.Zero(field.Type()))
+		return
+	}
+	if rel.Ptr {
+		field.Set(items[0].Addr())
+	} else {
+		field.Set(items[0])
+	}
+}
+
+// 获取对象中column列的值作为匹配的key
+func (s *Session) relationKey(v reflect.Value, column string) (string, error) {
+	value, ok, err := s.columnValue(v, column)
+	if err != nil || !ok {
+		return "", err
+	}
+	return fmt.Sprint(value), nil
+}
+
+// 获取对象中column列的值, 列没有映射时返回RelationKeyError, 值为空指针时ok为false
+func (s *Session) columnValue(v reflect.Value, column string) (value interface{}, ok bool, err error) {
+	index, err := s.fieldIndex(v.Type(), column)
+	if err != nil {
+		return nil, false, err
+	}
+	field, err := v.FieldByIndexErr(index)
+	if err != nil {
+		// 嵌入的struct指针为空
+		return nil, false, nil
+	}
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil, false, nil
+		}
+		field = field.Elem()
+	}
+	value = field.Interface()
+	// 例如sql.NullInt64, 使用写入数据库的值作为key
+	if valuer, isValuer := value.(driver.Valuer); isValuer {
+		if value, err = valuer.Value(); err != nil {
+			return nil, false, err
+		}
+	}
+	return value, value != nil, nil
+}
+
+type fieldIndexKey struct {
+	t      reflect.Type
+	column string
+}
+
+// 获取structType中映射到column列的字段下标, 每个类型和列只解析一次
+func (s *Session) fieldIndex(structType reflect.Type, column string) ([]int, error) {
+	key := fieldIndexKey{t: structType, column: column}
+	if index, ok := s.connection.fieldIndex.Load(key); ok {
+		return index.([]int), nil
+	}
+	objMapper, err := s.newMapper(reflect.New(structType).Interface())
+	if err != nil {
+		return nil, err
+	}
+	if err = objMapper.Parse(mapper.PARSE_UPDATE); err != nil {
+		return nil, err
+	}
+	index, ok := objMapper.GetFieldIndex(column)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s.%s", define.RelationKeyError, structType.Name(), column)
+	}
+	s.connection.fieldIndex.Store(key, index)
+	return index, nil
+}
@@ -0,0 +1,154 @@
+package sprydb
+
+import (
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Soul-Mate/sprydb/define"
+)
+
+type relUser struct {
+	Id      int64       `spry:"col:id"`
+	Name    string      `spry:"col:name"`
+	Posts   []*relPost  `spry:"has_many:posts;foreign_key:user_id"`
+	Profile *relProfile `spry:"has_one"`
+	Roles   []relRole   `spry:"many_to_many:user_roles;foreign_key:user_id;association_key:role_id"`
+}
+
+func (relUser) Table() string { return "users" }
+
+type relPost struct {
+	Id       int64        `spry:"col:id"`
+	UserId   int64        `spry:"col:user_id"`
+	Author   relUser      `spry:"belongs_to;foreign_key:user_id"`
+	Comments []relComment `spry:"has_many:comments;foreign_key:post_id"`
+}
+
+func (relPost) Table() string { return "posts" }
+
+type relComment struct {
+	Id     int64  `spry:"col:id"`
+	PostId int64  `spry:"col:post_id"`
+	Body   string `spry:"col:body"`
+}
+
+func (relComment) Table() string { return "comments" }
+
+type relProfile struct {
+	Id        int64 `spry:"col:id"`
+	RelUserId int64 `spry:"col:rel_user_id"`
+}
+
+func (relProfile) Table() string { return "profiles" }
+
+type relRole struct {
+	Id   int64  `spry:"col:id"`
+	Name string `spry:"col:name"`
+}
+
+func (relRole) Table() string { return "roles" }
+
+func TestSession_With(t *testing.T) {
+	conn, d := newFakeConnection(t, nil, nil)
+	d.results = [][][]driver.Value{
+		// users
+		{{int64(1), []byte("foo")}, {int64(2), []byte("bar")}},
+		// posts
+		{{int64(10), int64(1)}, {int64(11), int64(1)}, {int64(12), int64(2)}},
+		// comments
+		{{int64(100), int64(10), []byte("nice")}, {int64(101), int64(12), []byte("ok")}},
+	}
+	// 每次查询的列不同, 使用多组列名
+	d.columnsList = [][]string{{"id", "name"}, {"id", "user_id"}, {"id", "post_id", "body"}}
+
+	var users []relUser
+	if err := NewSession(conn).With("Posts.Comments").Get(&users); err != nil {
+		t.Fatal(err)
+	}
+	wantSql := []string{
+		"select `id`,`name` from `users`",
+		"select `id`,`user_id` from `posts` where `user_id` in (?,?)",
+		"select `id`,`post_id`,`body` from `comments` where `post_id` in (?,?,?)",
+	}
+	for i, want := range wantSql {
+		if d.queries[i] != want {
+			t.Errorf("query %d: got %s, want %s", i, d.queries[i], want)
+		}
+	}
+	if len(users[0].Posts) != 2 || len(users[1].Posts) != 1 || users[1].Posts[0].Id != 12 {
+		t.Fatalf("posts error: %+v", users)
+	}
+	if c := users[0].Posts[0].Comments; len(c) != 1 || c[0].Body != "nice" {
+		t.Errorf("comments error: %+v", c)
+	}
+	if c := users[0].Posts[1].Comments; c == nil || len(c) != 0 {
+		t.Errorf("empty comments should be an empty slice: %#v", c)
+	}
+	assertNoConnectionInUse(t, conn, "With")
+}
+
+func TestSession_WithBelongsToAndManyToMany(t *testing.T) {
+	conn, d := newFakeConnection(t, nil, nil)
+	d.results = [][][]driver.Value{
+		{{int64(10), int64(1)}},
+		{{int64(1), []byte("foo")}},
+		{{int64(1), int64(7)}, {int64(1), int64(8)}},
+		{{int64(8), []byte("admin")}, {int64(7), []byte("owner")}},
+	}
+	d.columnsList = [][]string{{"id", "user_id"}, {"id", "name"}, {"user_id", "role_id"}, {"id", "name"}}
+
+	post := relPost{}
+	if err := NewSession(conn).With("Author.Roles").First(&post); err != nil {
+		t.Fatal(err)
+	}
+	if post.Author.Name != "foo" {
+		t.Errorf("belongs_to error: %+v", post.Author)
+	}
+	if r := post.Author.Roles; len(r) != 2 || r[0].Name != "owner" || r[1].Name != "admin" {
+		t.Errorf("many_to_many error: %+v", r)
+	}
+	if want := "select `user_id`,`role_id` from `user_roles` where `user_id` in (?)"; d.queries[2] != want {
+		t.Errorf("pivot sql error: %s", d.queries[2])
+	}
+
+	d.results = [][][]driver.Value{{{int64(10), int64(1)}}}
+	d.columnsList = [][]string{{"id", "user_id"}}
+	err := NewSession(conn).With("Missing").First(&post)
+	if !errors.Is(err, define.RelationNotFoundError) {
+		t.Errorf("unknown relation should fail, got %v", err)
+	}
+}
+
+type relNullablePost struct {
+	Id     int64  `spry:"col:id"`
+	UserId *int64 `spry:"col:user_id"`
+}
+
+func (relNullablePost) Table() string { return "posts" }
+
+func TestSession_RelationKey(t *testing.T) {
+	conn, _ := newFakeConnection(t, nil, nil)
+	session := NewSession(conn)
+
+	userId := int64(3)
+	posts := []relNullablePost{{Id: 1, UserId: &userId}, {Id: 2}}
+	key, err := session.relationKey(reflect.ValueOf(posts).Index(0), "user_id")
+	if err != nil || key != "3" {
+		t.Errorf("relationKey error: %q %v", key, err)
+	}
+	// 空指针没有key
+	if key, err = session.relationKey(reflect.ValueOf(posts).Index(1), "user_id"); err != nil || key != "" {
+		t.Errorf("nil key error: %q %v", key, err)
+	}
+	// 字段下标按类型缓存
+	if _, ok := conn.fieldIndex.Load(fieldIndexKey{t: reflect.TypeOf(relNullablePost{}), column: "user_id"}); !ok {
+		t.Error("the field index should be cached")
+	}
+
+	_, err = session.relationKey(reflect.ValueOf(posts).Index(0), "author_id")
+	if !errors.Is(err, define.RelationKeyError) {
+		t.Errorf("unmapped key column should fail, got %v", err)
+	}
+}
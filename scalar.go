@@ -0,0 +1,188 @@
+package sprydb
+
+import (
+	"database/sql"
+	"reflect"
+
+	"github.com/Soul-Mate/sprydb/define"
+	"github.com/Soul-Mate/sprydb/mapper"
+)
+
+// 查询一列的所有值, dest是slice的指针, 元素可以是任意rows.Scan支持的类型
+// 列可能为NULL时使用指针或者sql.NullString等类型作为元素
+func (s *Session) Pluck(column string, dest interface{}) error {
+	var (
+		err    error
+		stmt   *sql.Stmt
+		rows   *sql.Rows
+		sqlStr string
+	)
+
+	defer s.resetBuilder()
+
+	if err = s.queryBuilder.GetErr(); err != nil {
+		return err
+	}
+
+	if dest == nil {
+		return define.ObjectNoneError
+	}
+
+	reflectType := reflect.TypeOf(dest)
+	if reflectType.Kind() != reflect.Ptr || reflectType.Elem().Kind() != reflect.Slice {
+		return define.UnsupportedTypeError
+	}
+	elem := reflect.ValueOf(dest).Elem()
+	elemType := reflectType.Elem().Elem()
+
+	s.queryBuilder.Select(column)
+
+	if sqlStr, err = s.grammar.CompileSelect(s.queryBuilder); err != nil {
+		return err
+	}
+
+	if s.connection.logging != nil {
+		defer s.connection.logging.Append(sqlStr, s.binding.GetBindings()...)
+	}
+
+	if stmt, err = s.prepare(sqlStr); err != nil {
+		return err
+	}
+
+	if rows, err = s.query(stmt, s.binding.GetBindings()...); err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := reflect.New(elemType)
+		if err = rows.Scan(item.Interface()); err != nil {
+			return err
+		}
+		elem.Set(reflect.Append(elem, item.Elem()))
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// 查询第一行的一列, dest是任意rows.Scan支持的类型的指针
+// 没有数据时返回ErrRecordNotFound
+func (s *Session) Value(column string, dest interface{}) error {
+	var (
+		err    error
+		stmt   *sql.Stmt
+		rows   *sql.Rows
+		sqlStr string
+	)
+
+	defer s.resetBuilder()
+
+	if err = s.queryBuilder.GetErr(); err != nil {
+		return err
+	}
+
+	if dest == nil {
+		return define.ObjectNoneError
+	}
+
+	if reflect.TypeOf(dest).Kind() != reflect.Ptr {
+		return define.UnsupportedTypeError
+	}
+
+	s.queryBuilder.Select(column).Take(1)
+
+	if sqlStr, err = s.grammar.CompileSelect(s.queryBuilder); err != nil {
+		return err
+	}
+
+	if s.connection.logging != nil {
+		defer s.connection.logging.Append(sqlStr, s.binding.GetBindings()...)
+	}
+
+	if stmt, err = s.prepare(sqlStr); err != nil {
+		return err
+	}
+
+	if rows, err = s.query(stmt, s.binding.GetBindings()...); err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if found, err := s.scanFirst(rows, []interface{}{dest}); err != nil || !found {
+		return err
+	}
+	return rows.Close()
+}
+
+// 查询多行数据, 以keyColumn列的值为key保存到dest
+// dest是*map[K]T或者*map[K]*T, T是struct, keyColumn需要映射到T的字段
+// key重复时后面的行覆盖前面的行, 整数和字符串之间不会转换, 类型不匹配时返回KeyTypeError
+func (s *Session) GetKeyed(keyColumn string, dest interface{}, column ...string) error {
+	var keyErr error
+
+	defer s.resetBuilder()
+
+	if dest == nil {
+		return define.ObjectNoneError
+	}
+
+	reflectType := reflect.TypeOf(dest)
+	if reflectType.Kind() != reflect.Ptr || reflectType.Elem().Kind() != reflect.Map {
+		return define.UnsupportedTypeError
+	}
+	mapType := reflectType.Elem()
+	structType, ptrElem := structElem(mapType.Elem())
+	if structType == nil {
+		return define.UnsupportedTypeError
+	}
+
+	elem := reflect.ValueOf(dest).Elem()
+	if elem.IsNil() {
+		elem.Set(reflect.MakeMap(mapType))
+	}
+
+	child := s.childSession()
+	err := s.queryStructs(structType, column, func(obj reflect.Value, objMapper *mapper.Mapper) {
+		if keyErr != nil {
+			return
+		}
+		key, ok := objMapper.GetValueByColumn(keyColumn)
+		if !ok || key == nil {
+			keyErr = define.KeyColumnError
+			return
+		}
+		keyValue := reflect.ValueOf(key)
+		if !keyConvertible(keyValue.Type(), mapType.Key()) {
+			keyErr = define.KeyTypeError
+			return
+		}
+		if keyErr = callHookValue(obj, mapper.AfterFind, child); keyErr != nil {
+			return
+		}
+		elem.SetMapIndex(keyValue.Convert(mapType.Key()), copyStruct(obj, ptrElem))
+	})
+	if err != nil {
+		return err
+	}
+	return keyErr
+}
+
+// 只允许数字之间或字符串之间的转换, 避免整数被reflect转换为对应的字符
+func keyConvertible(from, to reflect.Type) bool {
+	if !from.ConvertibleTo(to) {
+		return false
+	}
+	return isNumberKind(from.Kind()) == isNumberKind(to.Kind())
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package sprydb
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/Soul-Mate/sprydb/define"
+)
+
+func TestSession_Pluck(t *testing.T) {
+	conn, d := newFakeConnection(t, []string{"name"}, [][]driver.Value{
+		{[]byte("foo")}, {nil}, {[]byte("baz")},
+	})
+
+	var names []*string
+	if err := conn.Table("users").Pluck("name", &names); err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 3 || *names[0] != "foo" || names[1] != nil || *names[2] != "baz" {
+		t.Errorf("Pluck error: %v", names)
+	}
+	if want := "select `name` from `users`"; d.queries[0] != want {
+		t.Errorf("Pluck sql error: %s", d.queries[0])
+	}
+	assertNoConnectionInUse(t, conn, "Pluck")
+}
+
+func TestSession_Value(t *testing.T) {
+	conn, d := newFakeConnection(t, []string{"count"}, [][]driver.Value{{int64(42)}})
+
+	var count int
+	if err := conn.Table("users").Value("count(*) as count", &count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 42 {
+		t.Errorf("Value error: %d", count)
+	}
+	assertNoConnectionInUse(t, conn, "Value")
+
+	d.rows = nil
+	if err := conn.Table("users").Value("id", &count); err != define.ErrRecordNotFound {
+		t.Errorf("Value should return ErrRecordNotFound, got %v", err)
+	}
+}
+
+func TestSession_GetKeyed(t *testing.T) {
+	columns, rows := fakeUserRows()
+	conn, _ := newFakeConnection(t, columns, rows)
+
+	var byId map[int]fakeUser
+	if err := NewSession(conn).GetKeyed("id", &byId); err != nil {
+		t.Fatal(err)
+	}
+	if len(byId) != 3 || byId[2].Name != "bar" {
+		t.Errorf("GetKeyed error: %v", byId)
+	}
+
+	byName := map[string]*fakeUser{}
+	if err := NewSession(conn).GetKeyed("name", &byName); err != nil {
+		t.Fatal(err)
+	}
+	if len(byName) != 3 || byName["baz"].Id != 3 || byName["foo"] == byName["bar"] {
+		t.Errorf("GetKeyed pointer error: %v", byName)
+	}
+
+	if err := NewSession(conn).GetKeyed("missing", &byName); err != define.KeyColumnError {
+		t.Errorf("GetKeyed should return KeyColumnError, got %v", err)
+	}
+
+	// 整数列不能作为字符串key
+	byIdString := map[string]fakeUser{}
+	if err := NewSession(conn).GetKeyed("id", &byIdString); err != define.KeyTypeError {
+		t.Errorf("GetKeyed should return KeyTypeError, got %v", err)
+	}
+	if len(byIdString) != 0 {
+		t.Errorf("GetKeyed should not convert integers to string keys: %q", byIdString)
+	}
+	assertNoConnectionInUse(t, conn, "GetKeyed")
+}
+
+func TestSession_GetPointerElements(t *testing.T) {
+	columns, rows := fakeUserRows()
+	conn, _ := newFakeConnection(t, columns, rows)
+
+	var users []*fakeUser
+	if err := NewSession(conn).Get(&users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 3 || users[0].Name != "foo" || users[2].Name != "baz" {
+		t.Errorf("Get pointer elements error: %v", users)
+	}
+}
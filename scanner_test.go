@@ -0,0 +1,107 @@
+package sprydb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Soul-Mate/sprydb/define"
+)
+
+// 类似uuid.UUID的数组类型, 只能通过Scanner和Valuer读写
+type fakeUUID [4]byte
+
+func (u *fakeUUID) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return errors.New("unsupported uuid source")
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(u) {
+		return errors.New("invalid uuid")
+	}
+	copy(u[:], b)
+	return nil
+}
+
+func (u fakeUUID) Value() (driver.Value, error) {
+	return hex.EncodeToString(u[:]), nil
+}
+
+type scannerUser struct {
+	Id    fakeUUID       `spry:"col:id"`
+	Name  sql.NullString `spry:"col:name"`
+	Score *sql.NullInt64 `spry:"col:score"`
+}
+
+func (scannerUser) Table() string { return "users" }
+
+func TestSession_ScannerValuer(t *testing.T) {
+	conn, d := newFakeConnection(t, []string{"id", "name", "score"}, [][]driver.Value{
+		{"01020304", nil, int64(7)},
+	})
+
+	var user scannerUser
+	if err := conn.First(&user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Id != (fakeUUID{1, 2, 3, 4}) || user.Name.Valid || user.Score == nil || user.Score.Int64 != 7 {
+		t.Errorf("scan error: %+v", user)
+	}
+
+	user.Name = sql.NullString{String: "foo", Valid: true}
+	if _, _, err := conn.Insert(&user); err != nil {
+		t.Fatal(err)
+	}
+	args := d.args[len(d.args)-1]
+	if len(args) != 3 || args[0] != "01020304" || args[1] != "foo" || args[2] != int64(7) {
+		t.Errorf("insert bindings error: %v", args)
+	}
+
+	// 零值的Scanner字段不更新, 空指针字段更新为NULL
+	if _, err := conn.Where("id", "=", 1).Update(&scannerUser{Name: sql.NullString{String: "bar", Valid: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if sqlStr := d.execs[len(d.execs)-1]; sqlStr != "update `users` set `name` = ?,`score` = ? where `id` = ?" {
+		t.Errorf("update sql error: %s", sqlStr)
+	}
+}
+
+// 只实现了driver.Valuer的类型
+type upperName string
+
+func (n upperName) Value() (driver.Value, error) {
+	return strings.ToUpper(string(n)), nil
+}
+
+type valuerUser struct {
+	Id   int64     `spry:"col:id"`
+	Name upperName `spry:"col:name"`
+}
+
+func (valuerUser) Table() string { return "users" }
+
+func TestSession_ValuerOnly(t *testing.T) {
+	conn, d := newFakeConnection(t, []string{"id", "name"}, [][]driver.Value{{int64(1), "foo"}})
+
+	if _, _, err := conn.Insert(&valuerUser{Name: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	if args := d.args[len(d.args)-1]; len(args) != 2 || args[1] != "FOO" {
+		t.Errorf("insert bindings error: %v", args)
+	}
+
+	// 无法读取时返回错误, 而不是保留零值
+	var user valuerUser
+	if err := conn.First(&user); !errors.Is(err, define.ScannerTypeError) {
+		t.Errorf("want ScannerTypeError, got %v", err)
+	}
+}
@@ -11,8 +11,11 @@ import (
 	"github.com/Soul-Mate/sprydb/syntax"
 	"reflect"
 		"hash/crc32"
+	"fmt"
 )
 
+const defaultPK = "id"
+
 type Session struct {
 	err          error
 	ctx          context.Context
@@ -23,6 +26,10 @@ type Session struct {
 	connection   *Connection
 	transaction  *Transaction
 	queryBuilder *query.Builder
+	// 需要预加载的关联关系
+	with []string
+	// Track记录的对象每一列的值, 不随查询重置
+	snapshots map[interface{}]map[string]interface{}
 }
 
 func NewSession(connection *Connection) *Session {
@@ -30,9 +37,9 @@ func NewSession(connection *Connection) *Session {
 	session.ctx = context.Background()
 	session.syntax = syntax.NewSyntax(connection.driver)
 	session.binding = binding.NewBinding()
-	session.grammar = query.NewGrammarFactory(connection.driver, session.syntax, session.binding, connection.style)
 	session.stmtCache = make(map[uint32]*sql.Stmt)
 	session.connection = connection
+	session.grammar = session.newGrammar()
 	session.queryBuilder = query.NewBuilder(connection.driver, session.syntax, session.binding)
 	return session
 }
@@ -183,6 +190,11 @@ func (s *Session) OrWhereNotNull(column string) *Session {
 	return s
 }
 
+func (s *Session) WhereRow(columns []string, operator string, parameters []interface{}) *Session {
+	s.queryBuilder.WhereRow(columns, operator, parameters)
+	return s
+}
+
 func (s *Session) WhereSub(column, operator string, f func(b *query.Builder)) *Session {
 	s.queryBuilder.WhereSub(column, operator, f)
 	return s
@@ -194,7 +206,7 @@ func (s *Session) OrWhereSub(column, operator string, f func(b *query.Builder))
 }
 
 func (s *Session) OrderBy(column string, direction string) *Session {
-	s.queryBuilder.OrderBy(direction, column)
+	s.queryBuilder.OrderBy(column, direction)
 	return s
 }
 
@@ -203,6 +215,19 @@ func (s *Session) OrderByMulti(columns []string, direction string) *Session {
 	return s
 }
 
+// 使用f修改builder, 例如query.FromFilterSpec生成的过滤条件
+func (s *Session) Apply(f func(b *query.Builder)) *Session {
+	f(s.queryBuilder)
+	return s
+}
+
+// 预加载关联关系, 使用.分隔嵌套的关联关系, 例如 With("Posts", "Posts.Comments")
+// 只对Get, First, Find有效
+func (s *Session) With(relations ...string) *Session {
+	s.with = append(s.with, relations...)
+	return s
+}
+
 func (s *Session) Skip(n int) *Session {
 	s.queryBuilder.Skip(n)
 	return s
@@ -213,7 +238,8 @@ func (s *Session) Take(n int) *Session {
 	return s
 }
 
-func (s *Session) Find(id int, object interface{}, column ...string) error {
+// 根据主键查询, id可以是任意驱动支持的类型, 如int64, uint64, string, UUID
+func (s *Session) Find(id interface{}, object interface{}, column ...string) error {
 	var (
 		objMapper *mapper.Mapper
 		err       error
@@ -247,7 +273,7 @@ func (s *Session) Find(id int, object interface{}, column ...string) error {
 		return define.UnsupportedTypeError
 	}
 
-	if objMapper, err = mapper.NewMapper(object, s.syntax, s.connection.style); err != nil {
+	if objMapper, err = s.newMapper(object); err != nil {
 		return err
 	}
 
@@ -285,7 +311,12 @@ func (s *Session) Find(id int, object interface{}, column ...string) error {
 
 	// builder find sql
 	sqlStr = s.grammar.CompileFind(s.queryBuilder.GetDistinct(), columns, table, alias, objMapper.GetPK())
-	println(sqlStr)
+	s.queryBuilder.Table(table)
+	s.queryBuilder.SetAlias(alias)
+	s.useSoftDelete(objMapper)
+	if scope := s.grammar.CompileSoftDeleteScope(s.queryBuilder); scope != "" {
+		sqlStr += " and " + scope
+	}
 	// 追加查询sql日志
 	if s.connection.logging != nil {
 		defer s.connection.logging.Append(sqlStr, id)
@@ -298,22 +329,30 @@ func (s *Session) Find(id int, object interface{}, column ...string) error {
 	if rows, err = s.query(stmt, id); err != nil {
 		return err
 	}
+	defer rows.Close()
 
-	if !rows.Next() {
-		return nil
-	}
-
-	if err = rows.Scan(address...); err != nil {
+	if found, err := s.scanFirst(rows, address); err != nil || !found {
 		return err
 	}
 
 	// 赋值
-	objMapper.AssignAddressValue()
+	if err = objMapper.AssignAddressValue(); err != nil {
+		return err
+	}
 
-	return nil
+	if err = rows.Close(); err != nil {
+		return err
+	}
+	if len(s.with) > 0 {
+		if err = s.eagerLoad(reflect.ValueOf(object).Elem(), s.with); err != nil {
+			return err
+		}
+	}
+	return s.callHook(object, mapper.AfterFind)
 }
 
-func (s *Session) FindReturnMap(id int, pk string, column ...string) (map[string]interface{}, error) {
+// 根据主键查询并返回map, pk为空时使用默认主键id
+func (s *Session) FindReturnMap(id interface{}, pk string, column ...string) (map[string]interface{}, error) {
 	var (
 		rows     *sql.Rows
 		stmt     *sql.Stmt
@@ -321,8 +360,6 @@ func (s *Session) FindReturnMap(id int, pk string, column ...string) (map[string
 		distinct bool
 		table    string
 		alias    string
-		columns  []string
-		result   map[string]interface{}
 	)
 
 	defer s.resetBuilder()
@@ -335,9 +372,9 @@ func (s *Session) FindReturnMap(id int, pk string, column ...string) (map[string
 
 	alias = s.queryBuilder.GetAlias()
 	distinct = s.queryBuilder.GetDistinct()
-	// pk empty
+	// pk empty, use default primary key
 	if pk == "" {
-		return nil, errors.New("primary key can not be empty!")
+		pk = defaultPK
 	}
 
 	sqlStr := s.grammar.CompileFind(
@@ -355,34 +392,7 @@ func (s *Session) FindReturnMap(id int, pk string, column ...string) (map[string
 		return nil, err
 	}
 
-	if !rows.Next() {
-		return nil, nil
-	}
-
-	if columns, err = rows.Columns(); err != nil {
-		return nil, err
-	}
-
-	columnLen := len(columns)
-	values := make([]interface{}, columnLen)
-	address := make([]interface{}, columnLen)
-	for i := 0; i < columnLen; i++ {
-		address[i] = &values[i]
-	}
-
-	if err = rows.Scan(address...); err != nil {
-		return nil, err
-	}
-	result = make(map[string]interface{})
-
-	for i := 0; i < columnLen; i++ {
-		if _, ok := values[i].([]byte); ok {
-			result[columns[i]] = string(values[i].([]byte))
-		} else {
-			result[columns[i]] = values[i]
-		}
-	}
-	return result, nil
+	return s.scanFirstMap(rows)
 }
 
 func (s *Session) First(object interface{}, column ...string) error {
@@ -414,31 +424,10 @@ func (s *Session) First(object interface{}, column ...string) error {
 		return define.UnsupportedTypeError
 	}
 
-	if objMapper, err = mapper.NewMapper(object, s.syntax, s.connection.style); err != nil {
-		return err
-	}
-
-	buildTable := s.queryBuilder.GetTable()
-	buildAlias := s.queryBuilder.GetAlias()
-	if buildTable != "" {
-		objMapper.SetTable(buildTable)
-		objMapper.SetAlias(buildAlias)
-	}
-
-	joinMap := s.queryBuilder.GetJoinMap()
-	objMapper.SetJoinMap(&joinMap)
-	if err = objMapper.Parse(mapper.PARSE_SELECT); err != nil {
+	if objMapper, address, err = s.selectMapper(object, column...); err != nil {
 		return err
 	}
 
-	if buildTable == "" {
-		s.queryBuilder.Table(objMapper.GetTable())
-		s.queryBuilder.SetAlias(objMapper.GetAlias())
-	}
-
-	// connectionStmtCache column
-	address = s.prepareGiveColumnMapper(objMapper, column...)
-
 	s.queryBuilder.Skip(0).Take(1)
 
 	if sqlStr, err = s.grammar.CompileSelect(s.queryBuilder); err != nil {
@@ -456,26 +445,31 @@ func (s *Session) First(object interface{}, column ...string) error {
 	if rows, err = s.query(stmt, s.binding.GetBindings()...); err != nil {
 		return err
 	}
+	defer rows.Close()
 
-	if !rows.Next() {
-		return nil
+	if found, err := s.scanFirst(rows, address); err != nil || !found {
+		return err
 	}
-
-	if err = rows.Scan(address...); err != nil {
+	if err = objMapper.AssignAddressValue(); err != nil {
 		return err
 	}
-	objMapper.AssignAddressValue()
-	return nil
+	if err = rows.Close(); err != nil {
+		return err
+	}
+	if len(s.with) > 0 {
+		if err = s.eagerLoad(reflect.ValueOf(object).Elem(), s.with); err != nil {
+			return err
+		}
+	}
+	return s.callHook(object, mapper.AfterFind)
 }
 
 func (s *Session) FirstReturnMap() (map[string]interface{}, error) {
 	var (
-		rows    *sql.Rows
-		stmt    *sql.Stmt
-		err     error
-		sqlStr  string
-		columns []string
-		result  map[string]interface{}
+		rows   *sql.Rows
+		stmt   *sql.Stmt
+		err    error
+		sqlStr string
 	)
 
 	defer s.resetBuilder()
@@ -484,6 +478,8 @@ func (s *Session) FirstReturnMap() (map[string]interface{}, error) {
 		return nil, err
 	}
 
+	s.queryBuilder.Skip(0).Take(1)
+
 	if sqlStr, err = s.grammar.CompileSelect(s.queryBuilder); err != nil {
 		return nil, err
 	}
@@ -496,52 +492,22 @@ func (s *Session) FirstReturnMap() (map[string]interface{}, error) {
 		return nil, err
 	}
 
-	if !rows.Next() {
-		return nil, nil
-	}
-
-	if columns, err = rows.Columns(); err != nil {
-		return nil, err
-	}
-	columnLen := len(columns)
-	values := make([]interface{}, columnLen)
-	address := make([]interface{}, columnLen)
-	for i := 0; i < columnLen; i++ {
-		address[i] = &values[i]
-	}
-
-	if err = rows.Scan(address...); err != nil {
-		return nil, err
-	}
-
-	result = make(map[string]interface{})
-	for i := 0; i < columnLen; i++ {
-		if _, ok := values[i].([]byte); ok {
-			result[columns[i]] = string(values[i].([]byte))
-		} else {
-			result[columns[i]] = values[i]
-		}
-	}
-	return result, nil
+	return s.scanFirstMap(rows)
 }
 
+// objects可以是*[]T或者*[]*T, T是struct
 func (s *Session) Get(objects interface{}, column ...string) error {
 	var (
-		stmt         *sql.Stmt
-		err          error
 		reflectValue reflect.Value
 		reflectType  reflect.Type
-		obj          interface{}
-		sqlStr       string
-		rows         *sql.Rows
-		address      []interface{}
-		objMapper    *mapper.Mapper
+		structType   reflect.Type
+		ptrElem      bool
 	)
 
 	defer s.resetBuilder()
 
-	if err = s.queryBuilder.GetErr(); err != nil {
-		return err
+	if objects == nil {
+		return define.ObjectNoneError
 	}
 
 	reflectValue = reflect.ValueOf(objects)
@@ -554,36 +520,51 @@ func (s *Session) Get(objects interface{}, column ...string) error {
 		return errors.New("The method need slice type.")
 	}
 
-	if reflectType.Elem().Elem().Kind() != reflect.Struct {
+	if structType, ptrElem = structElem(reflectType.Elem().Elem()); structType == nil {
 		return errors.New("The elements in this slice should be struct.")
 	}
 
-	// create this type and get interface
-	obj = reflect.New(reflectType.Elem().Elem()).Interface()
-
-	if objMapper, err = mapper.NewMapper(obj, s.syntax, s.connection.style); err != nil {
+	// TODO 是否需要清空传递的指针对象,确保不会对传递的slice进行追加
+	elem := reflectValue.Elem()
+	start := elem.Len()
+	with := s.with
+	err := s.queryStructs(structType, column, func(obj reflect.Value, objMapper *mapper.Mapper) {
+		elem.Set(reflect.Append(elem, copyStruct(obj, ptrElem)))
+	})
+	if err != nil {
 		return err
 	}
-
-	buildTable := s.queryBuilder.GetTable()
-	buildAlias := s.queryBuilder.GetAlias()
-	if buildTable != "" {
-		objMapper.SetTable(buildTable)
-		objMapper.SetAlias(buildAlias)
+	loaded := elem.Slice(start, elem.Len())
+	if len(with) > 0 {
+		if err = s.eagerLoad(loaded, with); err != nil {
+			return err
+		}
 	}
+	return callHookValue(loaded, mapper.AfterFind, s.childSession())
+}
 
-	joinMap := s.queryBuilder.GetJoinMap()
-	objMapper.SetJoinMap(&joinMap)
-	if err = objMapper.Parse(mapper.PARSE_SELECT); err != nil {
+// 查询多行数据, 每一行扫描到structType类型的对象后调用f
+// 所有行共用同一个对象, f需要复制对象
+func (s *Session) queryStructs(structType reflect.Type, column []string, f func(obj reflect.Value, objMapper *mapper.Mapper)) error {
+	var (
+		stmt      *sql.Stmt
+		err       error
+		sqlStr    string
+		rows      *sql.Rows
+		address   []interface{}
+		objMapper *mapper.Mapper
+	)
+
+	if err = s.queryBuilder.GetErr(); err != nil {
 		return err
 	}
 
-	if buildTable == "" {
-		s.queryBuilder.Table(objMapper.GetTable())
-		s.queryBuilder.SetAlias(objMapper.GetAlias())
-	}
+	// create this type and get interface
+	obj := reflect.New(structType)
 
-	address = s.prepareGiveColumnMapper(objMapper, column...)
+	if objMapper, address, err = s.selectMapper(obj.Interface(), column...); err != nil {
+		return err
+	}
 
 	if sqlStr, err = s.grammar.CompileSelect(s.queryBuilder); err != nil {
 		return err
@@ -596,20 +577,42 @@ func (s *Session) Get(objects interface{}, column ...string) error {
 	if rows, err = s.query(stmt, s.binding.GetBindings()...); err != nil {
 		return err
 	}
+	defer rows.Close()
 
-	// TODO 是否需要清空传递的指针对象,确保不会对传递的slice进行追加
-	elem := reflectValue.Elem()
-	//newelem := reflect.New(reflectType.Elem()).Elem()
 	for rows.Next() {
 		if err = rows.Scan(address...); err != nil {
 			return err
 		}
-		objMapper.AssignAddressValue()
-		elem.Set(reflect.Append(elem, reflect.ValueOf(obj).Elem()))
-		//newelem.Set(reflect.Append(newelem, reflect.ValueOf(obj).Elem()))
+		if err = objMapper.AssignAddressValue(); err != nil {
+			return err
+		}
+		f(obj, objMapper)
 	}
-	//elem.Set(newelem)
-	return nil
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// 返回struct或者struct指针类型对应的struct类型, 其他类型返回nil
+func structElem(t reflect.Type) (structType reflect.Type, ptr bool) {
+	if t.Kind() == reflect.Struct {
+		return t, false
+	}
+	if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+		return t.Elem(), true
+	}
+	return nil, false
+}
+
+// 复制obj指向的struct, ptr为true时返回新对象的指针
+func copyStruct(obj reflect.Value, ptr bool) reflect.Value {
+	if !ptr {
+		return obj.Elem()
+	}
+	item := reflect.New(obj.Elem().Type())
+	item.Elem().Set(obj.Elem())
+	return item
 }
 
 func (s *Session) GetReturnMap() ([]map[string]interface{}, error) {
@@ -618,7 +621,7 @@ func (s *Session) GetReturnMap() ([]map[string]interface{}, error) {
 		sqlStr  string
 		stmt    *sql.Stmt
 		rows    *sql.Rows
-		columns []string
+		columns []*sql.ColumnType
 		results []map[string]interface{}
 	)
 
@@ -639,31 +642,233 @@ func (s *Session) GetReturnMap() ([]map[string]interface{}, error) {
 	if rows, err = s.query(stmt, s.binding.GetBindings()...); err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	if columns, err = rows.Columns(); err != nil {
+	if columns, err = rows.ColumnTypes(); err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		result, err := scanMap(rows, columns, s.connection.rawMapValues, s.connection.timeFormat)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	if err = rows.Err(); err != nil {
 		return nil, err
 	}
+	return results, rows.Close()
+}
+
+// 扫描结果集的第一行, 没有数据时found为false并返回notFound
+// 宽松模式下notFound为nil, 调用方需要根据found判断是否赋值
+func (s *Session) scanFirst(rows *sql.Rows, address []interface{}) (found bool, err error) {
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return false, err
+		}
+		return false, s.notFound()
+	}
+	return true, rows.Scan(address...)
+}
+
+// 扫描结果集的第一行为map, 扫描后关闭结果集
+func (s *Session) scanFirstMap(rows *sql.Rows) (map[string]interface{}, error) {
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, s.notFound()
+	}
+
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := scanMap(rows, columns, s.connection.rawMapValues, s.connection.timeFormat)
+	if err != nil {
+		return nil, err
+	}
+	return result, rows.Close()
+}
+
+// 扫描当前行为map, 根据列的数据库类型将[]byte转换为对应的go类型
+// raw为true时所有[]byte类型的值都转换为string
+func scanMap(rows *sql.Rows, columns []*sql.ColumnType, raw bool, timeFormat mapper.TimeFormat) (map[string]interface{}, error) {
 	columnLen := len(columns)
 	values := make([]interface{}, columnLen)
 	address := make([]interface{}, columnLen)
 	for i := 0; i < columnLen; i++ {
 		address[i] = &values[i]
 	}
-	for rows.Next() {
-		if err = rows.Scan(address...); err != nil {
+
+	if err := rows.Scan(address...); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{}, columnLen)
+	for i := 0; i < columnLen; i++ {
+		name := columns[i].Name()
+		b, ok := values[i].([]byte)
+		if !ok {
+			result[name] = values[i]
 			continue
 		}
-		result := make(map[string]interface{})
-		for i := 0; i < columnLen; i++ {
-			if _, ok := values[i].([]byte); ok {
-				result[columns[i]] = string(values[i].([]byte))
-			} else {
-				result[columns[i]] = values[i]
-			}
+		if raw {
+			result[name] = string(b)
+			continue
 		}
-		results = append(results, result)
+		value, err := convertColumnValue(columns[i].DatabaseTypeName(), b, timeFormat)
+		if err != nil {
+			return nil, fmt.Errorf("convert column %s: %w", name, err)
+		}
+		result[name] = value
 	}
-	return results, nil
+	return result, nil
+}
+
+// 根据多个主键查询, 结果按数据库返回的顺序追加到objects
+func (s *Session) FindMany(ids interface{}, objects interface{}, column ...string) error {
+	return s.findMany(ids, objects, false, column...)
+}
+
+// 根据多个主键查询, 结果按ids的顺序追加到objects
+func (s *Session) FindManyInOrder(ids interface{}, objects interface{}, column ...string) error {
+	return s.findMany(ids, objects, true, column...)
+}
+
+func (s *Session) findMany(ids interface{}, objects interface{}, ordered bool, column ...string) error {
+	var (
+		err       error
+		idValues  []interface{}
+		objMapper *mapper.Mapper
+	)
+
+	defer s.resetBuilder()
+
+	if err = s.queryBuilder.GetErr(); err != nil {
+		return err
+	}
+
+	if objects == nil {
+		return define.ObjectNoneError
+	}
+
+	reflectValue := reflect.ValueOf(objects)
+	reflectType := reflectValue.Type()
+	if reflectType.Kind() != reflect.Ptr || reflectType.Elem().Kind() != reflect.Slice {
+		return define.UnsupportedTypeError
+	}
+
+	// 与Get一样支持*[]T和*[]*T
+	structType, ptrElem := structElem(reflectType.Elem().Elem())
+	if structType == nil {
+		return define.UnsupportedTypeError
+	}
+
+	if idValues, err = interfaceSlice(ids); err != nil {
+		return err
+	}
+
+	if len(idValues) <= 0 {
+		return nil
+	}
+
+	// 使用slice元素的类型解析主键
+	if objMapper, err = s.parseModel(reflect.New(structType).Interface()); err != nil {
+		return err
+	}
+	pk := objMapper.GetPK()
+
+	elem := reflectValue.Elem()
+	start := elem.Len()
+	s.queryBuilder.WhereIn(pk, idValues...)
+	if err = s.Get(objects, column...); err != nil {
+		return err
+	}
+
+	if !ordered {
+		return nil
+	}
+
+	// 按照ids的顺序重新排列本次查询的结果
+	found := make(map[string]reflect.Value)
+	for i, n := start, elem.Len(); i < n; i++ {
+		item := elem.Index(i)
+		if !ptrElem {
+			item = item.Addr()
+		}
+		itemMapper, err := s.parseModel(item.Interface())
+		if err != nil {
+			return err
+		}
+		value, _, err := itemMapper.GetPKValue()
+		if err != nil {
+			return err
+		}
+		found[fmt.Sprint(value)] = elem.Index(i)
+	}
+
+	sorted := reflect.MakeSlice(elem.Type(), 0, elem.Len())
+	sorted = reflect.AppendSlice(sorted, elem.Slice(0, start))
+	for _, id := range idValues {
+		key := fmt.Sprint(id)
+		if item, ok := found[key]; ok {
+			sorted = reflect.Append(sorted, item)
+			delete(found, key)
+		}
+	}
+	elem.Set(sorted)
+	return nil
+}
+
+// 将任意类型的slice转换为[]interface{}
+func interfaceSlice(values interface{}) ([]interface{}, error) {
+	if v, ok := values.([]interface{}); ok {
+		return v, nil
+	}
+	rv := reflect.ValueOf(values)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, define.UnsupportedTypeError
+	}
+	result := make([]interface{}, rv.Len())
+	for i, n := 0, rv.Len(); i < n; i++ {
+		result[i] = rv.Index(i).Interface()
+	}
+	return result, nil
+}
+
+// 创建查询使用的映射器, 并将映射的table和查询的列设置到builder
+// 返回的地址用于rows.Scan
+func (s *Session) selectMapper(object interface{}, column ...string) (*mapper.Mapper, []interface{}, error) {
+	objMapper, err := s.newMapper(object)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	buildTable := s.queryBuilder.GetTable()
+	buildAlias := s.queryBuilder.GetAlias()
+	if buildTable != "" {
+		objMapper.SetTable(buildTable)
+		objMapper.SetAlias(buildAlias)
+	}
+
+	joinMap := s.queryBuilder.GetJoinMap()
+	objMapper.SetJoinMap(&joinMap)
+	if err = objMapper.Parse(mapper.PARSE_SELECT); err != nil {
+		return nil, nil, err
+	}
+
+	if buildTable == "" {
+		s.queryBuilder.Table(objMapper.GetTable())
+		s.queryBuilder.SetAlias(objMapper.GetAlias())
+	}
+	s.useSoftDelete(objMapper)
+
+	return objMapper, s.prepareGiveColumnMapper(objMapper, column...), nil
 }
 
 func (s *Session) prepareGiveColumnMapper(m *mapper.Mapper, column ...string) []interface{} {
@@ -685,6 +890,11 @@ func (s *Session) prepareGiveColumnMapper(m *mapper.Mapper, column ...string) []
 }
 
 func (s *Session) Insert(object interface{}) (lastInsertId, rowsAffected int64, err error) {
+	return s.insert(object, nil)
+}
+
+// pkMapper不为nil时在AfterInsert之前回写生成的主键, 钩子中可以读取到新的主键
+func (s *Session) insert(object interface{}, pkMapper *mapper.Mapper) (lastInsertId, rowsAffected int64, err error) {
 	var (
 		stmt     *sql.Stmt
 		sqlStr   string
@@ -694,6 +904,12 @@ func (s *Session) Insert(object interface{}) (lastInsertId, rowsAffected int64,
 
 	defer s.resetBuilder()
 
+	if err = s.callHook(object, mapper.BeforeInsert); err != nil {
+		return
+	}
+
+	s.touchTimestamps(object, mapper.PARSE_INSERT)
+
 	if sqlStr, bindings, err = s.grammar.CompileInsert(object, s.queryBuilder); err != nil {
 		return
 	}
@@ -702,6 +918,10 @@ func (s *Session) Insert(object interface{}) (lastInsertId, rowsAffected int64,
 		return
 	}
 
+	if s.connection.logging != nil {
+		defer s.connection.logging.Append(sqlStr, bindings...)
+	}
+
 	if stmt, err = s.prepare(sqlStr); err != nil {
 		return
 	}
@@ -717,15 +937,30 @@ func (s *Session) Insert(object interface{}) (lastInsertId, rowsAffected int64,
 	if rowsAffected, err = result.RowsAffected(); err != nil {
 		return
 	}
+
+	if pkMapper != nil {
+		if err = pkMapper.SetPKValue(lastInsertId); err != nil && err != define.PrimaryKeyTypeError {
+			return
+		}
+	}
+
+	err = s.callHook(object, mapper.AfterInsert)
 	return
 }
 
 func (s *Session) Update(value interface{}) (rowsAffected int64, err error) {
+	return s.update(value, true)
+}
+
+// before为false时调用方已经执行了BeforeUpdate钩子和时间字段的填充
+func (s *Session) update(value interface{}, before bool) (rowsAffected int64, err error) {
 	var (
-		stmt     *sql.Stmt
-		sqlStr   string
-		result   sql.Result
-		bindings []interface{}
+		stmt          *sql.Stmt
+		sqlStr        string
+		result        sql.Result
+		bindings      []interface{}
+		objMapper     *mapper.Mapper
+		versionMapper *mapper.Mapper
 	)
 
 	defer s.resetBuilder()
@@ -734,6 +969,24 @@ func (s *Session) Update(value interface{}) (rowsAffected int64, err error) {
 		return 0, err
 	}
 
+	if before {
+		if err = s.callHook(value, mapper.BeforeUpdate); err != nil {
+			return
+		}
+		s.touchTimestamps(value, mapper.PARSE_UPDATE)
+	}
+
+	if objMapper, err = s.parseUpdateModel(value); err != nil {
+		return
+	}
+	if objMapper != nil {
+		s.useSoftDelete(objMapper)
+		versionMapper = s.useVersion(objMapper)
+		if err = s.queryBuilder.GetErr(); err != nil {
+			return
+		}
+	}
+
 	if sqlStr, bindings, err = s.grammar.CompileUpdate(value, s.queryBuilder); err != nil {
 		return
 	}
@@ -743,6 +996,66 @@ func (s *Session) Update(value interface{}) (rowsAffected int64, err error) {
 	}
 	bindings = s.binding.PrepareUpdateBinding(bindings)
 
+	if s.connection.logging != nil {
+		defer s.connection.logging.Append(sqlStr, bindings...)
+	}
+
+	if stmt, err = s.prepare(sqlStr); err != nil {
+		return
+	}
+
+	if result, err = s.exec(stmt, bindings...); err != nil {
+		return
+	}
+
+	if rowsAffected, err = result.RowsAffected(); err != nil {
+		return
+	}
+
+	if versionMapper != nil {
+		if rowsAffected == 0 {
+			return 0, define.ErrStaleObject
+		}
+		versionMapper.IncrVersion()
+	}
+
+	err = s.callHook(value, mapper.AfterUpdate)
+	return
+}
+
+// 对列进行原子自增, extra中的列会一起更新
+func (s *Session) Increment(column string, amount interface{}, extra map[string]interface{}) (rowsAffected int64, err error) {
+	return s.increment(column, "+", amount, extra)
+}
+
+// 对列进行原子自减, extra中的列会一起更新
+func (s *Session) Decrement(column string, amount interface{}, extra map[string]interface{}) (rowsAffected int64, err error) {
+	return s.increment(column, "-", amount, extra)
+}
+
+func (s *Session) increment(column, operator string, amount interface{}, extra map[string]interface{}) (rowsAffected int64, err error) {
+	var (
+		stmt     *sql.Stmt
+		sqlStr   string
+		result   sql.Result
+		bindings []interface{}
+	)
+
+	defer s.resetBuilder()
+
+	if err = s.queryBuilder.GetErr(); err != nil {
+		return 0, err
+	}
+
+	if sqlStr, bindings, err = s.grammar.CompileIncrement(column, operator, amount, extra, s.queryBuilder); err != nil {
+		return
+	}
+	bindings = s.binding.PrepareUpdateBinding(bindings)
+
+	if s.connection.logging != nil {
+		defer s.connection.logging.Append(sqlStr, bindings...)
+	}
+
 	if stmt, err = s.prepare(sqlStr); err != nil {
 		return
 	}
@@ -754,11 +1067,23 @@ func (s *Session) Update(value interface{}) (rowsAffected int64, err error) {
 	return result.RowsAffected()
 }
 
+// 删除数据, 设置了软删除的列时更新软删除的列
+// 软删除的列来自映射的对象, 只使用Table时不知道表是否使用软删除, 会执行物理删除,
+// 软删除的表需要使用Model(&T{})或DeleteModel
 func (s *Session) Delete() (rowsAffected int64, err error) {
+	if column := s.queryBuilder.GetSoftDelete(); column != "" {
+		deletedAt := s.queryBuilder.GetSoftDeleteTimeFormat().Format(s.now())
+		return s.Update(map[string]interface{}{column: deletedAt})
+	}
+	return s.delete()
+}
+
+func (s *Session) delete() (rowsAffected int64, err error) {
 	var (
-		stmt   *sql.Stmt
-		result sql.Result
-		sqlStr string
+		stmt     *sql.Stmt
+		result   sql.Result
+		sqlStr   string
+		bindings []interface{}
 	)
 
 	defer s.resetBuilder()
@@ -770,18 +1095,152 @@ func (s *Session) Delete() (rowsAffected int64, err error) {
 	if sqlStr, err = s.grammar.CompileDelete(s.queryBuilder); err != nil {
 		return
 	}
+	bindings = s.binding.PrepareDeleteBinding()
+
+	if s.connection.logging != nil {
+		defer s.connection.logging.Append(sqlStr, bindings...)
+	}
 
 	if stmt, err = s.prepare(sqlStr); err != nil {
 		return
 	}
 
-	if result, err = s.exec(stmt, s.binding.PrepareDeleteBinding()...); err != nil {
+	if result, err = s.exec(stmt, bindings...); err != nil {
 		return
 	}
 
 	return result.RowsAffected()
 }
 
+// 根据主键保存对象
+// 主键是零值时插入数据并回写生成的主键, 否则根据主键更新数据
+// 只有整数类型的主键会被回写, string, UUID等类型的主键需要在保存前由调用方生成
+func (s *Session) Save(object interface{}) error {
+	var (
+		err       error
+		pk        interface{}
+		ok        bool
+		objMapper *mapper.Mapper
+	)
+
+	defer s.resetBuilder()
+
+	if err = s.queryBuilder.GetErr(); err != nil {
+		return err
+	}
+
+	if objMapper, err = s.parseModel(object); err != nil {
+		return err
+	}
+
+	if pk, ok, err = objMapper.GetPKValue(); err != nil {
+		return err
+	}
+
+	if !ok {
+		_, _, err = s.insert(object, objMapper)
+		return err
+	}
+
+	_, err = s.Where(objMapper.GetPK(), "=", pk).Update(object)
+	return err
+}
+
+// 根据主键删除对象
+func (s *Session) DeleteModel(object interface{}) (rowsAffected int64, err error) {
+	var (
+		pk        interface{}
+		ok        bool
+		objMapper *mapper.Mapper
+	)
+
+	defer s.resetBuilder()
+
+	if err = s.queryBuilder.GetErr(); err != nil {
+		return 0, err
+	}
+
+	if objMapper, err = s.parseModel(object); err != nil {
+		return
+	}
+
+	if pk, ok, err = objMapper.GetPKValue(); err != nil {
+		return
+	}
+
+	if !ok {
+		return 0, define.PrimaryKeyZeroError
+	}
+
+	if err = s.callHook(object, mapper.BeforeDelete); err != nil {
+		return
+	}
+
+	if s.queryBuilder.GetTable() == "" {
+		s.queryBuilder.Table(objMapper.GetTable())
+		s.queryBuilder.SetAlias(objMapper.GetAlias())
+	}
+
+	return s.Where(objMapper.GetPK(), "=", pk).Delete()
+}
+
+// 解析模型对象, 对象必须是struct指针
+func (s *Session) parseModel(object interface{}) (*mapper.Mapper, error) {
+	if object == nil {
+		return nil, define.ObjectNoneError
+	}
+
+	t := reflect.TypeOf(object)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, define.UnsupportedTypeError
+	}
+
+	objMapper, err := s.newMapper(object)
+	if err != nil {
+		return nil, err
+	}
+
+	if buildTable := s.queryBuilder.GetTable(); buildTable != "" {
+		objMapper.SetTable(buildTable)
+		objMapper.SetAlias(s.queryBuilder.GetAlias())
+	}
+
+	if err = objMapper.Parse(mapper.PARSE_UPDATE); err != nil {
+		return nil, err
+	}
+	s.useSoftDelete(objMapper)
+	return objMapper, nil
+}
+
+// 解析更新的struct或struct指针, 用于软删除和乐观锁的条件, 其它类型返回nil
+func (s *Session) parseUpdateModel(value interface{}) (*mapper.Mapper, error) {
+	if value == nil {
+		return nil, nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	objMapper, err := s.newMapper(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if buildTable := s.queryBuilder.GetTable(); buildTable != "" {
+		objMapper.SetTable(buildTable)
+		objMapper.SetAlias(s.queryBuilder.GetAlias())
+	}
+
+	if err = objMapper.Parse(mapper.PARSE_UPDATE); err != nil {
+		return nil, err
+	}
+	return objMapper, nil
+}
+
 func (s *Session) Exec(query string, args ...interface{}) (sql.Result, error) {
 	stmt, err := s.prepare(query)
 	if err != nil {
@@ -849,6 +1308,38 @@ func (s *Session) ToDeleteSql() (sql string, err error) {
 	return
 }
 
+// 没有查询到数据时返回的错误
+// 连接设置了宽松模式时返回nil, 与旧的行为保持一致
+func (s *Session) notFound() error {
+	if s.connection.lenientNotFound {
+		return nil
+	}
+	return define.ErrRecordNotFound
+}
+
+// 重置查询状态, binding和grammar需要与builder一起重置,
+// 否则下一次查询会使用上一次残留的参数
 func (s *Session) resetBuilder() {
-	s.queryBuilder = query.NewBuilder(s.connection.driver, s.syntax, binding.NewBinding())
+	s.binding = binding.NewBinding()
+	s.grammar = s.newGrammar()
+	s.queryBuilder = query.NewBuilder(s.connection.driver, s.syntax, s.binding)
+	s.with = nil
+}
+
+func (s *Session) newGrammar() query.GrammarInterface {
+	grammar := query.NewGrammarFactory(s.connection.driver, s.syntax, s.binding, s.connection.style)
+	if grammar != nil {
+		grammar.SetTimeFormat(&s.connection.timeFormat)
+	}
+	return grammar
+}
+
+// 创建使用连接设置的映射器
+func (s *Session) newMapper(object interface{}) (*mapper.Mapper, error) {
+	objMapper, err := mapper.NewMapper(object, s.syntax, s.connection.style)
+	if err != nil {
+		return nil, err
+	}
+	objMapper.SetTimeFormat(&s.connection.timeFormat)
+	return objMapper, nil
 }
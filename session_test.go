@@ -0,0 +1,597 @@
+package sprydb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Soul-Mate/sprydb/define"
+	"github.com/Soul-Mate/sprydb/logging"
+)
+
+// fakeDriver 是一个只在测试中使用的database/sql驱动,
+// 所有查询都返回同一份固定的结果集, 并记录执行过的sql
+type fakeDriver struct {
+	mu      sync.Mutex
+	columns []string
+	// 列的数据库类型, 为空时不提供类型
+	types []string
+	rows  [][]driver.Value
+	// 结果集读取到第iterErrAt行时返回错误, 0表示不返回错误
+	iterErrAt int
+	// 不为空时每次查询依次返回其中的一个结果集, 用完之后返回空结果集
+	results [][][]driver.Value
+	// 与results对应的列名, 为空时使用columns
+	columnsList [][]string
+	queries     []string
+	args        [][]driver.Value
+	execs       []string
+	// 为true时exec不影响任何行
+	zeroAffected bool
+}
+
+var (
+	fakeDriverOnce sync.Once
+	fakeDrivers    = make(map[string]*fakeDriver)
+	fakeDriversMu  sync.Mutex
+	errFakeIter    = errors.New("fake iteration error")
+)
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeDriversMu.Lock()
+	defer fakeDriversMu.Unlock()
+	return &fakeConn{driver: fakeDrivers[name]}, nil
+}
+
+func (d *fakeDriver) recordQuery(query string, args []driver.Value) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, query)
+	d.args = append(d.args, args)
+}
+
+func (d *fakeDriver) recordExec(query string, args []driver.Value) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.execs = append(d.execs, query)
+	d.args = append(d.args, args)
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return &fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (t *fakeTx) Commit() error { return nil }
+
+func (t *fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error { return nil }
+
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	d := s.conn.driver
+	d.recordExec(s.query, args)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.zeroAffected {
+		return fakeResult{affected: 0}, nil
+	}
+	return fakeResult{affected: 1}, nil
+}
+
+// fakeResult 插入的主键总是1
+type fakeResult struct {
+	affected int64
+}
+
+func (r fakeResult) LastInsertId() (int64, error) { return 1, nil }
+
+func (r fakeResult) RowsAffected() (int64, error) { return r.affected, nil }
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	d := s.conn.driver
+	d.recordQuery(s.query, args)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	rows, columns := d.rows, d.columns
+	if len(d.columnsList) > 0 {
+		columns, d.columnsList = d.columnsList[0], d.columnsList[1:]
+	}
+	if d.results != nil {
+		rows = nil
+		if len(d.results) > 0 {
+			rows, d.results = d.results[0], d.results[1:]
+		}
+	}
+	return &fakeRows{columns: columns, types: d.types, rows: rows, iterErrAt: d.iterErrAt}, nil
+}
+
+type fakeRows struct {
+	columns   []string
+	types     []string
+	rows      [][]driver.Value
+	iterErrAt int
+	next      int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(index int) string {
+	if index < len(r.types) {
+		return r.types[index]
+	}
+	return ""
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.iterErrAt > 0 && r.next+1 == r.iterErrAt {
+		return errFakeIter
+	}
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+// 创建使用fakeDriver的连接, 每个测试使用独立的数据源
+func newFakeConnection(t *testing.T, columns []string, rows [][]driver.Value) (*Connection, *fakeDriver) {
+	fakeDriverOnce.Do(func() {
+		sql.Register("sprydb_fake", &fakeDriver{})
+	})
+	d := &fakeDriver{columns: columns, rows: rows}
+	fakeDriversMu.Lock()
+	fakeDrivers[t.Name()] = d
+	fakeDriversMu.Unlock()
+
+	db, err := sql.Open("sprydb_fake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := &Connection{
+		DB:     db,
+		driver: "mysql",
+		cache:  new(sync.Map),
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	return conn, d
+}
+
+type fakeUser struct {
+	Id   int64  `spry:"col:id"`
+	Name string `spry:"col:name"`
+}
+
+func (fakeUser) Table() string {
+	return "users"
+}
+
+func fakeUserRows() ([]string, [][]driver.Value) {
+	return []string{"id", "name"}, [][]driver.Value{
+		{int64(1), []byte("foo")},
+		{int64(2), []byte("bar")},
+		{int64(3), []byte("baz")},
+	}
+}
+
+func assertNoConnectionInUse(t *testing.T, conn *Connection, name string) {
+	t.Helper()
+	if inUse := conn.DB.Stats().InUse; inUse != 0 {
+		t.Errorf("%s leaked %d connection(s)", name, inUse)
+	}
+}
+
+func TestSession_TerminalsCloseRows(t *testing.T) {
+	columns, rows := fakeUserRows()
+	conn, _ := newFakeConnection(t, columns, rows)
+
+	user := fakeUser{}
+	if err := conn.Find(1, &user); err != nil {
+		t.Fatal(err)
+	}
+	assertNoConnectionInUse(t, conn, "Find")
+
+	if err := NewSession(conn).First(&user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Id != 1 || user.Name != "foo" {
+		t.Errorf("First scan error: %+v", user)
+	}
+	assertNoConnectionInUse(t, conn, "First")
+
+	var users []fakeUser
+	if err := NewSession(conn).Get(&users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 3 {
+		t.Errorf("Get scan error: %+v", users)
+	}
+	assertNoConnectionInUse(t, conn, "Get")
+
+	if _, err := conn.Table("users").FindReturnMap(1, ""); err != nil {
+		t.Fatal(err)
+	}
+	assertNoConnectionInUse(t, conn, "FindReturnMap")
+
+	if _, err := conn.Table("users").FirstReturnMap(); err != nil {
+		t.Fatal(err)
+	}
+	assertNoConnectionInUse(t, conn, "FirstReturnMap")
+
+	results, err := conn.Table("users").GetReturnMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || results[1]["name"] != "bar" {
+		t.Errorf("GetReturnMap scan error: %v", results)
+	}
+	assertNoConnectionInUse(t, conn, "GetReturnMap")
+}
+
+func TestSession_IterationError(t *testing.T) {
+	columns, rows := fakeUserRows()
+	conn, d := newFakeConnection(t, columns, rows)
+	d.iterErrAt = 2
+
+	var users []fakeUser
+	if err := NewSession(conn).Get(&users); err != errFakeIter {
+		t.Errorf("Get should return iteration error, got %v", err)
+	}
+	assertNoConnectionInUse(t, conn, "Get")
+
+	if _, err := conn.Table("users").GetReturnMap(); err != errFakeIter {
+		t.Errorf("GetReturnMap should return iteration error, got %v", err)
+	}
+	assertNoConnectionInUse(t, conn, "GetReturnMap")
+
+	// 第一行就返回错误, 不应该被当作没有数据
+	d.iterErrAt = 1
+	if err := NewSession(conn).First(&fakeUser{}); err != errFakeIter {
+		t.Errorf("First should return iteration error, got %v", err)
+	}
+	assertNoConnectionInUse(t, conn, "First")
+}
+
+func TestSession_ScanError(t *testing.T) {
+	conn, _ := newFakeConnection(t, []string{"id", "name"}, [][]driver.Value{
+		{"not a number", []byte("foo")},
+	})
+
+	var users []fakeUser
+	if err := NewSession(conn).Get(&users); err == nil {
+		t.Error("Get should return scan error")
+	}
+	assertNoConnectionInUse(t, conn, "Get")
+}
+
+func TestSession_RecordNotFound(t *testing.T) {
+	conn, _ := newFakeConnection(t, []string{"id", "name"}, nil)
+
+	err := NewSession(conn).First(&fakeUser{})
+	if !errors.Is(err, define.ErrRecordNotFound) || !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("First should return ErrRecordNotFound, got %v", err)
+	}
+	if err = conn.Find(1, &fakeUser{}); err != define.ErrRecordNotFound {
+		t.Errorf("Find should return ErrRecordNotFound, got %v", err)
+	}
+	if _, err = conn.Table("users").FirstReturnMap(); err != define.ErrRecordNotFound {
+		t.Errorf("FirstReturnMap should return ErrRecordNotFound, got %v", err)
+	}
+	if _, err = conn.Table("users").FindReturnMap(1, ""); err != define.ErrRecordNotFound {
+		t.Errorf("FindReturnMap should return ErrRecordNotFound, got %v", err)
+	}
+	assertNoConnectionInUse(t, conn, "First")
+
+	// 多行查询没有数据时不是错误
+	var users []fakeUser
+	if err = NewSession(conn).Get(&users); err != nil || len(users) != 0 {
+		t.Errorf("Get should not return error on empty result, got %v", err)
+	}
+
+	conn.SetLenientNotFound(true)
+	user := fakeUser{Id: 7}
+	if err = conn.Find(1, &user); err != nil {
+		t.Errorf("lenient Find should not return error, got %v", err)
+	}
+	if user.Id != 7 {
+		t.Errorf("lenient Find should leave the object untouched: %+v", user)
+	}
+	if err = NewSession(conn).First(&fakeUser{}); err != nil {
+		t.Errorf("lenient First should not return error, got %v", err)
+	}
+	result, err := conn.Table("users").FirstReturnMap()
+	if err != nil || result != nil {
+		t.Errorf("lenient FirstReturnMap should return nil, got %v %v", result, err)
+	}
+	if result, err = conn.Table("users").FindReturnMap(1, ""); err != nil || result != nil {
+		t.Errorf("lenient FindReturnMap should return nil, got %v %v", result, err)
+	}
+}
+
+func TestSession_ResetBindings(t *testing.T) {
+	columns, rows := fakeUserRows()
+	conn, d := newFakeConnection(t, columns, rows)
+
+	session := NewSession(conn)
+	var users []fakeUser
+	if err := session.Where("name", "=", "foo").Get(&users); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Where("id", ">", 1).Get(&users); err != nil {
+		t.Fatal(err)
+	}
+	last := d.args[len(d.args)-1]
+	if len(last) != 1 || last[0] != int64(1) {
+		t.Errorf("the second query should only bind its own parameters, got %v", last)
+	}
+	if !strings.HasSuffix(d.queries[len(d.queries)-1], "where `id` > ?") {
+		t.Errorf("unexpected sql: %s", d.queries[len(d.queries)-1])
+	}
+}
+
+func TestSession_WriteQueryLog(t *testing.T) {
+	conn, _ := newFakeConnection(t, nil, nil)
+	conn.logging = logging.NewLogging()
+
+	if _, _, err := conn.Insert(&fakeUser{Name: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Table("users").Where("id", "=", 1).Update(map[string]interface{}{"name": "bar"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Table("users").Where("id", "=", 1).Increment("score", 2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Table("users").Where("id", "=", 1).Delete(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"insert into `users` (`id`,`name`) values (0,\"foo\");",
+		"update `users` set `name` = \"bar\" where `id` = 1",
+		"update `users` set `score` = `score` + 2 where `id` = 1",
+		"delete from `users` where `id` = 1",
+	}
+	queries := conn.GetRawQueryLog()
+	if len(queries) != len(expected) {
+		t.Fatalf("every write should be logged, got %v", queries)
+	}
+	for i := range expected {
+		if queries[i] != expected[i] {
+			t.Errorf("unexpected query log: %s", queries[i])
+		}
+	}
+}
+
+type fakeUUIDUser struct {
+	Id   string `spry:"col:id"`
+	Name string `spry:"col:name"`
+}
+
+func (fakeUUIDUser) Table() string {
+	return "users"
+}
+
+func TestSession_Save(t *testing.T) {
+	conn, d := newFakeConnection(t, nil, nil)
+
+	user := fakeUser{Name: "foo"}
+	if err := conn.Save(&user); err != nil {
+		t.Fatal(err)
+	}
+	if d.execs[0] != "insert into `users` (`id`,`name`) values (?,?);" {
+		t.Errorf("Save should insert the object: %s", d.execs[0])
+	}
+	if user.Id != 1 {
+		t.Errorf("Save should write back the generated id, got %d", user.Id)
+	}
+
+	user.Name = "bar"
+	if err := conn.Save(&user); err != nil {
+		t.Fatal(err)
+	}
+	if d.execs[1] != "update `users` set `id` = ?,`name` = ? where `id` = ?" {
+		t.Errorf("Save should update by primary key: %s", d.execs[1])
+	}
+	if last := d.args[1]; len(last) != 3 || last[1] != "bar" || last[2] != int64(1) {
+		t.Errorf("unexpected update bindings: %v", last)
+	}
+
+	// 非整数主键不回写
+	uuidUser := fakeUUIDUser{Name: "foo"}
+	if err := conn.Save(&uuidUser); err != nil {
+		t.Fatal(err)
+	}
+	if uuidUser.Id != "" {
+		t.Errorf("Save should not write back a string primary key, got %q", uuidUser.Id)
+	}
+
+	// 空指针主键分配后回写
+	ptrUser := fakePtrUser{Name: "foo"}
+	if err := conn.Save(&ptrUser); err != nil {
+		t.Fatal(err)
+	}
+	if ptrUser.Id == nil || *ptrUser.Id != 1 {
+		t.Errorf("Save should write back a pointer primary key, got %v", ptrUser.Id)
+	}
+	ptrUser.Name = "bar"
+	if err := conn.Save(&ptrUser); err != nil {
+		t.Fatal(err)
+	}
+	if last := d.execs[len(d.execs)-1]; last != "update `users` set `id` = ?,`name` = ? where `id` = ?" {
+		t.Errorf("Save should update a pointer primary key object: %s", last)
+	}
+}
+
+type fakePtrUser struct {
+	Id   *int64 `spry:"col:id"`
+	Name string `spry:"col:name"`
+}
+
+func (fakePtrUser) Table() string {
+	return "users"
+}
+
+func TestSession_DeleteModel(t *testing.T) {
+	conn, d := newFakeConnection(t, nil, nil)
+
+	if _, err := conn.DeleteModel(&fakeUser{}); err != define.PrimaryKeyZeroError {
+		t.Errorf("DeleteModel should reject a zero primary key, got %v", err)
+	}
+
+	affected, err := conn.DeleteModel(&fakeUser{Id: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affected != 1 {
+		t.Errorf("unexpected rows affected: %d", affected)
+	}
+	if len(d.execs) != 1 || d.execs[0] != "delete from `users` where `id` = ?" {
+		t.Errorf("DeleteModel should delete by primary key: %v", d.execs)
+	}
+	if last := d.args[0]; len(last) != 1 || last[0] != int64(5) {
+		t.Errorf("unexpected delete bindings: %v", last)
+	}
+}
+
+type fakeSnowflakeUser struct {
+	Id   uint64 `spry:"col:id"`
+	Name string `spry:"col:name"`
+}
+
+func (fakeSnowflakeUser) Table() string {
+	return "users"
+}
+
+func TestSession_FindGenericID(t *testing.T) {
+	id := uint64(1) << 62
+	conn, d := newFakeConnection(t, []string{"id", "name"}, [][]driver.Value{
+		{int64(id), []byte("foo")},
+	})
+
+	user := fakeSnowflakeUser{}
+	if err := conn.Find(id, &user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Id != id || user.Name != "foo" {
+		t.Errorf("Find scan error: %+v", user)
+	}
+	if !strings.HasSuffix(d.queries[0], "where `id` = ?") {
+		t.Errorf("unexpected sql: %s", d.queries[0])
+	}
+	if last := d.args[0]; len(last) != 1 || last[0] != int64(id) {
+		t.Errorf("unexpected bindings: %v", last)
+	}
+
+	uuid := "6f1c2d9e-8a4b-4c1f-9b7e-2d3a4f5b6c7d"
+	d.rows = [][]driver.Value{{[]byte(uuid), []byte("bar")}}
+	uuidUser := fakeUUIDUser{}
+	if err := conn.Find(uuid, &uuidUser); err != nil {
+		t.Fatal(err)
+	}
+	if uuidUser.Id != uuid || uuidUser.Name != "bar" {
+		t.Errorf("Find scan error: %+v", uuidUser)
+	}
+	if last := d.args[1]; len(last) != 1 || last[0] != uuid {
+		t.Errorf("unexpected bindings: %v", last)
+	}
+}
+
+func TestSession_FindMany(t *testing.T) {
+	columns, rows := fakeUserRows()
+	conn, d := newFakeConnection(t, columns, rows)
+
+	var users []fakeUser
+	if err := NewSession(conn).FindMany([]int64{3, 1, 2}, &users); err != nil {
+		t.Fatal(err)
+	}
+	if d.queries[0] != "select `id`,`name` from `users` where `id` in (?,?,?)" {
+		t.Errorf("unexpected sql: %s", d.queries[0])
+	}
+	if last := d.args[0]; len(last) != 3 || last[0] != int64(3) || last[1] != int64(1) || last[2] != int64(2) {
+		t.Errorf("unexpected bindings: %v", last)
+	}
+	// FindMany保持数据库返回的顺序
+	if len(users) != 3 || users[0].Id != 1 || users[2].Id != 3 {
+		t.Errorf("FindMany scan error: %+v", users)
+	}
+
+	if err := NewSession(conn).FindMany([]int64{}, &users); err != nil || len(d.queries) != 1 {
+		t.Errorf("FindMany should not query with empty ids, got %v", err)
+	}
+	if err := NewSession(conn).FindMany([]int64{1}, &[]int64{}); err != define.UnsupportedTypeError {
+		t.Errorf("FindMany should reject non struct elements, got %v", err)
+	}
+}
+
+func TestSession_FindManyInOrder(t *testing.T) {
+	columns, rows := fakeUserRows()
+	conn, d := newFakeConnection(t, columns, rows)
+
+	// 4不存在, 结果中跳过
+	var users []*fakeUser
+	if err := NewSession(conn).FindManyInOrder([]int64{3, 4, 1, 2}, &users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 3 || users[0].Id != 3 || users[1].Id != 1 || users[2].Id != 2 {
+		t.Errorf("FindManyInOrder order error: %+v %+v %+v", users[0], users[1], users[2])
+	}
+	if users[0].Name != "baz" {
+		t.Errorf("FindManyInOrder scan error: %+v", users[0])
+	}
+
+	snowflakes := []uint64{uint64(1) << 62, uint64(1)<<62 + 1}
+	d.rows = [][]driver.Value{
+		{int64(snowflakes[0]), []byte("foo")},
+		{int64(snowflakes[1]), []byte("bar")},
+	}
+	var snowflakeUsers []fakeSnowflakeUser
+	if err := NewSession(conn).FindManyInOrder([]uint64{snowflakes[1], snowflakes[0]}, &snowflakeUsers); err != nil {
+		t.Fatal(err)
+	}
+	if len(snowflakeUsers) != 2 || snowflakeUsers[0].Name != "bar" || snowflakeUsers[1].Name != "foo" {
+		t.Errorf("FindManyInOrder uint64 order error: %+v", snowflakeUsers)
+	}
+
+	d.rows = [][]driver.Value{
+		{[]byte("a"), []byte("foo")},
+		{[]byte("b"), []byte("bar")},
+		{[]byte("c"), []byte("baz")},
+	}
+	var uuidUsers []fakeUUIDUser
+	if err := NewSession(conn).FindManyInOrder([]string{"c", "x", "a", "b"}, &uuidUsers); err != nil {
+		t.Fatal(err)
+	}
+	if len(uuidUsers) != 3 || uuidUsers[0].Id != "c" || uuidUsers[1].Id != "a" || uuidUsers[2].Id != "b" {
+		t.Errorf("FindManyInOrder string order error: %+v", uuidUsers)
+	}
+	last := d.args[len(d.args)-1]
+	if len(last) != 4 || last[1] != "x" {
+		t.Errorf("unexpected bindings: %v", last)
+	}
+}
@@ -0,0 +1,109 @@
+package sprydb
+
+import (
+	"database/sql"
+
+	"github.com/Soul-Mate/sprydb/define"
+	"github.com/Soul-Mate/sprydb/mapper"
+)
+
+// 映射的对象声明了软删除的列时, 将列和写入时间的格式设置到builder
+func (s *Session) useSoftDelete(objMapper *mapper.Mapper) {
+	if column := objMapper.GetSoftDeleteColumn(); column != "" && s.queryBuilder.GetSoftDelete() == "" {
+		format, err := objMapper.GetSoftDeleteTimeFormat()
+		if err != nil {
+			s.queryBuilder.SetErr(err)
+			return
+		}
+		s.queryBuilder.SoftDelete(column).SoftDeleteTimeFormat(format)
+	}
+}
+
+// 使用对象的表和软删除的列, 用于Count, Delete等不需要传入对象的操作
+func (s *Session) Model(object interface{}) *Session {
+	objMapper, err := s.parseModel(object)
+	if err != nil {
+		s.queryBuilder.SetErr(err)
+		return s
+	}
+	if s.queryBuilder.GetTable() == "" {
+		s.queryBuilder.Table(objMapper.GetTable())
+		s.queryBuilder.SetAlias(objMapper.GetAlias())
+	}
+	return s
+}
+
+// 查询包括被软删除的数据
+func (s *Session) WithTrashed() *Session {
+	s.queryBuilder.WithTrashed()
+	return s
+}
+
+// 只查询被软删除的数据
+func (s *Session) OnlyTrashed() *Session {
+	s.queryBuilder.OnlyTrashed()
+	return s
+}
+
+// 恢复被软删除的数据
+func (s *Session) Restore() (rowsAffected int64, err error) {
+	column := s.queryBuilder.GetSoftDelete()
+	if column == "" {
+		s.resetBuilder()
+		return 0, define.SoftDeleteNoneError
+	}
+	s.queryBuilder.OnlyTrashed()
+	return s.Update(map[string]interface{}{column: nil})
+}
+
+// 删除数据, 忽略软删除, 包括已经被软删除的数据
+func (s *Session) ForceDelete() (rowsAffected int64, err error) {
+	s.queryBuilder.WithTrashed()
+	return s.delete()
+}
+
+// 查询数量, column为空时使用count(*)
+func (s *Session) Count(column ...string) (count int64, err error) {
+	var (
+		stmt   *sql.Stmt
+		rows   *sql.Rows
+		sqlStr string
+	)
+
+	defer s.resetBuilder()
+
+	if err = s.queryBuilder.GetErr(); err != nil {
+		return 0, err
+	}
+
+	countColumn := "*"
+	if len(column) > 0 {
+		countColumn = column[0]
+	}
+	s.queryBuilder.Aggregate("count", countColumn)
+
+	if sqlStr, err = s.grammar.CompileSelect(s.queryBuilder); err != nil {
+		return 0, err
+	}
+
+	if s.connection.logging != nil {
+		defer s.connection.logging.Append(sqlStr, s.binding.GetBindings()...)
+	}
+
+	if stmt, err = s.prepare(sqlStr); err != nil {
+		return 0, err
+	}
+
+	if rows, err = s.query(stmt, s.binding.GetBindings()...); err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return 0, rows.Err()
+	}
+	if err = rows.Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, rows.Close()
+}
@@ -0,0 +1,147 @@
+package sprydb
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+type softUser struct {
+	Id        int64      `spry:"col:id"`
+	Name      string     `spry:"col:name"`
+	DeletedAt *time.Time `spry:"soft_delete"`
+}
+
+func (softUser) Table() string { return "users" }
+
+func TestSession_SoftDelete(t *testing.T) {
+	now := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = time.Now }()
+
+	conn, d := newFakeConnection(t, nil, nil)
+	userColumns := []string{"id", "name", "deleted_at"}
+	d.columnsList = [][]string{userColumns, userColumns, {"aggregate"}, {"aggregate"}}
+	d.results = [][][]driver.Value{
+		nil,
+		{{int64(1), "foo", nil}},
+		{{int64(2)}},
+		{{int64(3)}},
+	}
+
+	var users []softUser
+	if err := conn.Where("name", "=", "foo").OrWhere("name", "=", "bar").Get(&users); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Find(1, &softUser{}); err != nil {
+		t.Fatal(err)
+	}
+	count, err := conn.Model(&softUser{}).OnlyTrashed().Count()
+	if err != nil || count != 2 {
+		t.Errorf("Count error: %d %v", count, err)
+	}
+	if _, err = conn.Model(&softUser{}).WithTrashed().Count("id"); err != nil {
+		t.Fatal(err)
+	}
+	wantQueries := []string{
+		"select `id`,`name`,`deleted_at` from `users` where (`name` = ? or `name` = ?) and `deleted_at` is null",
+		"select `id`,`name`,`deleted_at` from `users` where `id` = ? and `deleted_at` is null",
+		"select count(*) as `aggregate` from `users` where `deleted_at` is not null",
+		"select count(`id`) as `aggregate` from `users`",
+	}
+	for i, want := range wantQueries {
+		if d.queries[i] != want {
+			t.Errorf("query %d: got %s, want %s", i, d.queries[i], want)
+		}
+	}
+
+	if _, err = conn.Model(&softUser{}).Where("id", "=", 1).Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = conn.Model(&softUser{}).Where("id", "=", 1).Restore(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = conn.Model(&softUser{}).Where("id", "=", 1).ForceDelete(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = conn.DeleteModel(&softUser{Id: 2}); err != nil {
+		t.Fatal(err)
+	}
+	wantExecs := []string{
+		"update `users` set `deleted_at` = ? where `id` = ? and `deleted_at` is null",
+		"update `users` set `deleted_at` = ? where `id` = ? and `deleted_at` is not null",
+		"delete from `users` where `id` = ?",
+		"update `users` set `deleted_at` = ? where `id` = ? and `deleted_at` is null",
+	}
+	for i, want := range wantExecs {
+		if d.execs[i] != want {
+			t.Errorf("exec %d: got %s, want %s", i, d.execs[i], want)
+		}
+	}
+	if args := d.args[4]; len(args) != 2 || args[0] != "2018-01-02 03:04:05" {
+		t.Errorf("soft delete bindings error: %v", args)
+	}
+	if args := d.args[5]; len(args) != 2 || args[0] != nil {
+		t.Errorf("restore bindings error: %v", args)
+	}
+}
+
+type softTzUser struct {
+	Id        int64      `spry:"col:id"`
+	DeletedAt *time.Time `spry:"soft_delete;time_layout:datetime(3);tz:Asia/Shanghai"`
+}
+
+func (softTzUser) Table() string { return "users" }
+
+func TestSession_SoftDeleteTimeFormat(t *testing.T) {
+	now := time.Date(2018, 1, 2, 3, 4, 5, 678000000, time.UTC)
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = time.Now }()
+
+	conn, d := newFakeConnection(t, nil, nil)
+	conn.SetTimestampPrecision(time.Millisecond)
+	conn.SetTimeLayout("datetime(6)")
+	conn.SetTimeLocation(time.FixedZone("UTC+1", 3600))
+
+	if _, err := conn.Model(&softUser{}).Where("id", "=", 1).Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if args := d.args[0]; args[0] != "2018-01-02 04:04:05.678000" {
+		t.Errorf("soft delete should use the connection time format: %v", args)
+	}
+
+	// 字段的time_layout和tz优先
+	if _, err := conn.Model(&softTzUser{}).Where("id", "=", 1).Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if args := d.args[1]; args[0] != "2018-01-02 11:04:05.678" {
+		t.Errorf("soft delete should use the field time format: %v", args)
+	}
+
+	// 只使用Table时不知道软删除的列, 执行物理删除
+	if _, err := conn.Table("users").Where("id", "=", 1).Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if d.execs[2] != "delete from `users` where `id` = ?" {
+		t.Errorf("Table delete should be a hard delete: %s", d.execs[2])
+	}
+}
+
+func TestSession_SoftDeleteUpdate(t *testing.T) {
+	conn, d := newFakeConnection(t, nil, nil)
+
+	if _, err := conn.Where("id", "=", 1).Update(&softUser{Id: 1, Name: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "update `users` set `id` = ?,`name` = ?,`deleted_at` = ? where `id` = ? and `deleted_at` is null"
+	if d.execs[0] != want {
+		t.Errorf("Update should skip soft deleted rows: %s", d.execs[0])
+	}
+
+	if _, err := NewSession(conn).WithTrashed().Where("id", "=", 1).Update(&softUser{Id: 1, Name: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	if want = "update `users` set `id` = ?,`name` = ?,`deleted_at` = ? where `id` = ?"; d.execs[1] != want {
+		t.Errorf("WithTrashed Update should include soft deleted rows: %s", d.execs[1])
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"reflect"
 	"bytes"
+	"database/sql/driver"
 )
 
 type SyntaxAbstract struct {
@@ -136,7 +137,7 @@ func (s *SyntaxAbstract) ParameterByInterfaceToString(any interface{}) (Paramete
 	switch any.(type) {
 	case int, int8, int16, int32, int64,
 	uint, uint8, uint16, uint32, uint64,
-	float32, float64, string, bool:
+	float32, float64, string, bool, driver.Valuer:
 		ParameterStr = "?"
 	case []int, []int8, []int32, []int64,
 	[]uint, []uint8, []uint16, []uint32, []uint64,
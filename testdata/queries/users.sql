@@ -0,0 +1,10 @@
+-- 用户相关的查询
+
+-- name: GetActiveUsers
+select id, name
+from users
+where status = :status
+order by id;
+
+-- name: DeactivateUser
+update users set status = 0 where id = ?;
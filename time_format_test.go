@@ -0,0 +1,65 @@
+package sprydb
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+type timeFormatEvent struct {
+	Id       int64      `spry:"col:id"`
+	Day      time.Time  `spry:"col:day;time_layout:date"`
+	Precise  time.Time  `spry:"col:precise;time_layout:datetime(6)"`
+	Local    *time.Time `spry:"col:local;tz:Asia/Shanghai"`
+	Received time.Time  `spry:"col:received"`
+}
+
+func (timeFormatEvent) Table() string { return "events" }
+
+func TestSession_TimeFormat(t *testing.T) {
+	conn, d := newFakeConnection(t, []string{"id", "day", "precise", "local", "received"}, [][]driver.Value{
+		{int64(1), []byte("2018-01-02"), []byte("2018-01-02 03:04:05.123456"), []byte("2018-01-02 11:04:05"),
+			time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)},
+	})
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	var event timeFormatEvent
+	if err = conn.First(&event); err != nil {
+		t.Fatal(err)
+	}
+	if !event.Day.Equal(time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("date error: %v", event.Day)
+	}
+	if event.Precise.Nanosecond() != 123456000 {
+		t.Errorf("microseconds lost: %v", event.Precise)
+	}
+	if event.Local == nil || !event.Local.Equal(time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("time zone error: %v", event.Local)
+	}
+	// 驱动返回time.Time时database/sql转换为RFC3339Nano
+	if !event.Received.Equal(time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("driver time error: %v", event.Received)
+	}
+
+	// 连接的设置对没有声明time_layout和tz的字段生效
+	conn.SetTimeLayout("datetime(3)")
+	conn.SetTimeLocation(shanghai)
+	if _, _, err = conn.Insert(&event); err != nil {
+		t.Fatal(err)
+	}
+	args := d.args[len(d.args)-1]
+	want := []interface{}{int64(1), "2018-01-02", "2018-01-02 11:04:05.123456", "2018-01-02 11:04:05.000", "2018-01-02 11:04:05.000"}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("insert binding %d: got %v, want %v", i, args[i], want[i])
+		}
+	}
+
+	d.rows = [][]driver.Value{{int64(1), []byte("not a date"), nil, nil, nil}}
+	if err = conn.First(&event); err == nil {
+		t.Error("want parse error")
+	}
+}
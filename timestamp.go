@@ -0,0 +1,49 @@
+package sprydb
+
+import (
+	"reflect"
+	"time"
+)
+
+// 获取当前时间, 测试中可以替换
+var nowFunc = time.Now
+
+// 按照连接设置的时区和精度获取当前时间
+func (s *Session) now() time.Time {
+	now := nowFunc()
+	if s.connection.timestampLocation != nil {
+		now = now.In(s.connection.timestampLocation)
+	}
+	precision := s.connection.timestampPrecision
+	if precision <= 0 {
+		precision = time.Second
+	}
+	return now.Truncate(precision)
+}
+
+// 为插入或更新的对象中的自动时间字段赋值
+// 支持struct指针, struct的slice以及slice指针, 其它类型不处理
+func (s *Session) touchTimestamps(object interface{}, parseType int) {
+	if object == nil {
+		return
+	}
+	v := reflect.ValueOf(object)
+	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Slice {
+		v = v.Elem()
+	}
+	switch {
+	case v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct:
+		s.touchObjectTimestamps(v, parseType, s.now())
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct:
+		now := s.now()
+		for i := 0; i < v.Len(); i++ {
+			s.touchObjectTimestamps(v.Index(i).Addr(), parseType, now)
+		}
+	}
+}
+
+func (s *Session) touchObjectTimestamps(v reflect.Value, parseType int, now time.Time) {
+	if objMapper, err := s.newMapper(v.Interface()); err == nil {
+		objMapper.SetTimestamps(parseType, now)
+	}
+}
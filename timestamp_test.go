@@ -0,0 +1,106 @@
+package sprydb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Soul-Mate/sprydb/mapper"
+)
+
+type timestampPost struct {
+	Id        int64  `spry:"col:id"`
+	Title     string `spry:"col:title"`
+	CreatedAt time.Time
+	Modified  *time.Time `spry:"col:modified;auto_update_time"`
+}
+
+func (timestampPost) Table() string { return "posts" }
+
+func TestSession_Timestamps(t *testing.T) {
+	now := time.Date(2018, 1, 2, 3, 4, 5, 678000000, time.UTC)
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = time.Now }()
+
+	conn, d := newFakeConnection(t, nil, nil)
+	loc := time.FixedZone("CST", 8*3600)
+	conn.SetTimestampLocation(loc)
+	conn.SetTimestampPrecision(time.Millisecond)
+
+	post := &timestampPost{Title: "foo"}
+	if _, _, err := conn.Insert(post); err != nil {
+		t.Fatal(err)
+	}
+	want := now.In(loc)
+	if !post.CreatedAt.Equal(want) || post.CreatedAt.Location() != loc {
+		t.Errorf("CreatedAt error: %v", post.CreatedAt)
+	}
+	if post.Modified == nil || !post.Modified.Equal(want) {
+		t.Errorf("Modified error: %v", post.Modified)
+	}
+	if d.execs[0] != "insert into `posts` (`id`,`title`,`created_at`,`modified`) values (?,?,?,?);" {
+		t.Errorf("insert sql error: %s", d.execs[0])
+	}
+	if args := d.args[0]; args[2] != "2018-01-02 11:04:05" || args[3] != "2018-01-02 11:04:05" {
+		t.Errorf("insert bindings error: %v", args)
+	}
+
+	// 已经设置的创建时间不会被覆盖
+	created := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	posts := []timestampPost{{Title: "bar", CreatedAt: created}, {Title: "baz"}}
+	if _, _, err := conn.Insert(posts); err != nil {
+		t.Fatal(err)
+	}
+	if !posts[0].CreatedAt.Equal(created) || !posts[1].CreatedAt.Equal(want) || posts[0].Modified == nil {
+		t.Errorf("multi insert timestamps error: %v", posts)
+	}
+
+	nowFunc = func() time.Time { return now.Add(time.Hour) }
+	update := &timestampPost{Title: "qux"}
+	if _, err := conn.Where("id", "=", 1).Update(update); err != nil {
+		t.Fatal(err)
+	}
+	if !update.CreatedAt.IsZero() || update.Modified == nil || !update.Modified.Equal(want.Add(time.Hour)) {
+		t.Errorf("update timestamps error: %v %v", update.CreatedAt, update.Modified)
+	}
+	if d.execs[2] != "update `posts` set `title` = ?,`modified` = ? where `id` = ?" {
+		t.Errorf("update sql error: %s", d.execs[2])
+	}
+}
+
+type timestampLayoutPost struct {
+	Id        int64        `spry:"col:id"`
+	CreatedAt mapper.Time  `spry:"col:created_at"`
+	UpdatedAt *mapper.Time `spry:"col:updated_at"`
+}
+
+func (timestampLayoutPost) Table() string { return "posts" }
+
+func TestSession_TimestampsMapperTime(t *testing.T) {
+	now := time.Date(2018, 1, 2, 3, 4, 5, 678000000, time.UTC)
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = time.Now }()
+
+	conn, d := newFakeConnection(t, nil, nil)
+	conn.SetTimestampPrecision(time.Millisecond)
+
+	post := &timestampLayoutPost{UpdatedAt: mapper.NewTime(time.Time{}, "datetime(3)")}
+	if _, _, err := conn.Insert(post); err != nil {
+		t.Fatal(err)
+	}
+	if !post.CreatedAt.Equal(now) || post.UpdatedAt == nil || !post.UpdatedAt.Equal(now) {
+		t.Errorf("mapper.Time timestamps error: %v %v", post.CreatedAt, post.UpdatedAt)
+	}
+	// UpdatedAt使用自身的格式
+	if args := d.args[0]; len(args) != 3 || args[1] != "2018-01-02 03:04:05" || args[2] != "2018-01-02 03:04:05.678" {
+		t.Errorf("insert bindings error: %v", args)
+	}
+
+	later := now.Add(time.Hour)
+	nowFunc = func() time.Time { return later }
+	if _, err := conn.Where("id", "=", 1).Update(post); err != nil {
+		t.Fatal(err)
+	}
+	if !post.CreatedAt.Equal(now) || !post.UpdatedAt.Equal(later) {
+		t.Errorf("update should only touch UpdatedAt: %v %v", post.CreatedAt, post.UpdatedAt)
+	}
+}
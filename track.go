@@ -0,0 +1,131 @@
+package sprydb
+
+import (
+	"reflect"
+
+	"github.com/Soul-Mate/sprydb/define"
+	"github.com/Soul-Mate/sprydb/mapper"
+)
+
+// 被跟踪对象的一列修改, Old和New是写入数据库的值,
+// 例如时间字段是格式化后的字符串
+type Change struct {
+	Column string
+	Old    interface{}
+	New    interface{}
+}
+
+// 记录对象当前的值, 之后可以使用Changes比较修改, UpdateChanged只更新修改的列
+// 对象必须是struct指针, 快照保存在session中, 需要使用同一个session
+func (s *Session) Track(object interface{}) error {
+	columns, values, err := s.columnValues(object)
+	if err != nil {
+		return err
+	}
+	snap := make(map[string]interface{}, len(columns))
+	for i, c := range columns {
+		snap[c] = values[i]
+	}
+	if s.snapshots == nil {
+		s.snapshots = make(map[interface{}]map[string]interface{})
+	}
+	s.snapshots[object] = snap
+	return nil
+}
+
+// 停止跟踪对象
+func (s *Session) Untrack(object interface{}) {
+	delete(s.snapshots, object)
+}
+
+// 比较对象与Track时的值, 按照映射的列的顺序返回修改的列
+func (s *Session) Changes(object interface{}) ([]Change, error) {
+	snap, ok := s.snapshots[object]
+	if !ok {
+		return nil, define.NotTrackedError
+	}
+	columns, values, err := s.columnValues(object)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	for i, c := range columns {
+		if old, ok := snap[c]; !ok || !reflect.DeepEqual(old, values[i]) {
+			changes = append(changes, Change{Column: c, Old: old, New: values[i]})
+		}
+	}
+	return changes, nil
+}
+
+// 根据主键只更新被跟踪对象修改过的列, 零值也会更新
+// 先执行BeforeUpdate钩子再比较, 钩子中的修改也会被更新, 更新时间只在有修改时填充
+// 没有修改时不执行sql, 更新成功后重新记录对象的值
+func (s *Session) UpdateChanged(object interface{}) (rowsAffected int64, err error) {
+	var (
+		pk        interface{}
+		ok        bool
+		changes   []Change
+		objMapper *mapper.Mapper
+	)
+
+	defer s.resetBuilder()
+
+	if err = s.queryBuilder.GetErr(); err != nil {
+		return 0, err
+	}
+
+	if _, ok = s.snapshots[object]; !ok {
+		return 0, define.NotTrackedError
+	}
+
+	if err = s.callHook(object, mapper.BeforeUpdate); err != nil {
+		return
+	}
+
+	if changes, err = s.Changes(object); err != nil || len(changes) <= 0 {
+		return
+	}
+	s.touchTimestamps(object, mapper.PARSE_UPDATE)
+
+	if objMapper, err = s.parseModel(object); err != nil {
+		return
+	}
+
+	if pk, ok, err = objMapper.GetPKValue(); err != nil {
+		return
+	}
+
+	if !ok {
+		return 0, define.PrimaryKeyZeroError
+	}
+
+	columns := make([]string, len(changes))
+	for i, change := range changes {
+		columns[i] = change.Column
+	}
+	s.queryBuilder.UpdateColumns(columns...)
+
+	if rowsAffected, err = s.Where(objMapper.GetPK(), "=", pk).update(object, false); err != nil {
+		return
+	}
+	return rowsAffected, s.Track(object)
+}
+
+// 解析对象每一列写入数据库的值
+func (s *Session) columnValues(object interface{}) ([]string, []interface{}, error) {
+	if object == nil {
+		return nil, nil, define.ObjectNoneError
+	}
+	t := reflect.TypeOf(object)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, nil, define.UnsupportedTypeError
+	}
+	objMapper, err := s.newMapper(object)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = objMapper.Parse(mapper.PARSE_UPDATE); err != nil {
+		return nil, nil, err
+	}
+	return objMapper.GetColumnValues()
+}
@@ -0,0 +1,103 @@
+package sprydb
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Soul-Mate/sprydb/define"
+)
+
+type trackedUser struct {
+	Id        int64     `spry:"col:id"`
+	Name      string    `spry:"col:name"`
+	Score     int       `spry:"col:score"`
+	UpdatedAt time.Time `spry:"col:updated_at"`
+}
+
+func (trackedUser) Table() string { return "users" }
+
+func TestSession_Track(t *testing.T) {
+	now := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = time.Now }()
+
+	conn, d := newFakeConnection(t, []string{"id", "name", "score", "updated_at"}, [][]driver.Value{
+		{int64(1), "foo", int64(10), []byte("2017-01-01 00:00:00")},
+	})
+	session := NewSession(conn)
+
+	var user trackedUser
+	if err := session.First(&user); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := session.Changes(&user); !errors.Is(err, define.NotTrackedError) {
+		t.Errorf("want NotTrackedError, got %v", err)
+	}
+	if err := session.Track(&user); err != nil {
+		t.Fatal(err)
+	}
+
+	// 没有修改时不执行sql
+	if n, err := session.UpdateChanged(&user); err != nil || n != 0 || len(d.execs) != 0 {
+		t.Errorf("UpdateChanged without changes: %d %v %v", n, err, d.execs)
+	}
+
+	user.Score = 0
+	changes, err := session.Changes(&user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Column != "score" || changes[0].Old != int(10) || changes[0].New != int(0) {
+		t.Errorf("Changes error: %+v", changes)
+	}
+
+	if _, err = session.UpdateChanged(&user); err != nil {
+		t.Fatal(err)
+	}
+	if d.execs[0] != "update `users` set `score` = ?,`updated_at` = ? where `id` = ?" {
+		t.Errorf("update sql error: %s", d.execs[0])
+	}
+	if args := d.args[len(d.args)-1]; len(args) != 3 || args[0] != int64(0) || args[1] != "2018-01-02 03:04:05" {
+		t.Errorf("update bindings error: %v", args)
+	}
+	// 更新成功后重新记录快照
+	if changes, err = session.Changes(&user); err != nil || len(changes) != 0 {
+		t.Errorf("snapshot not refreshed: %+v %v", changes, err)
+	}
+}
+
+type hookTrackedUser struct {
+	Id   int64  `spry:"col:id"`
+	Name string `spry:"col:name"`
+	Slug string `spry:"col:slug"`
+}
+
+func (hookTrackedUser) Table() string { return "users" }
+
+func (u *hookTrackedUser) BeforeUpdate(s *Session) error {
+	u.Slug = "slug-" + u.Name
+	return nil
+}
+
+func TestSession_UpdateChangedHook(t *testing.T) {
+	conn, d := newFakeConnection(t, nil, nil)
+	session := NewSession(conn)
+
+	user := hookTrackedUser{Id: 1, Name: "foo", Slug: "slug-foo"}
+	if err := session.Track(&user); err != nil {
+		t.Fatal(err)
+	}
+	user.Name = "bar"
+	if _, err := session.UpdateChanged(&user); err != nil {
+		t.Fatal(err)
+	}
+	// BeforeUpdate修改的列也会被更新
+	if d.execs[0] != "update `users` set `name` = ?,`slug` = ? where `id` = ?" {
+		t.Errorf("update sql error: %s", d.execs[0])
+	}
+	if args := d.args[0]; len(args) != 3 || args[0] != "bar" || args[1] != "slug-bar" {
+		t.Errorf("update bindings error: %v", args)
+	}
+}
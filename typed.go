@@ -0,0 +1,182 @@
+package sprydb
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/Soul-Mate/sprydb/define"
+)
+
+// 泛型查询, T是映射的struct类型
+// 结果的类型在编译期确定, 不需要传入对象指针
+// go的类型约束不能限制T为struct, T是否是struct(不能是struct指针)只能在创建查询时检查
+type TypedQuery[T any] struct {
+	session *Session
+}
+
+// 创建T类型的查询, T不是struct时返回UnsupportedTypeError
+func Query[T any](conn *Connection) (*TypedQuery[T], error) {
+	return newTypedQuery[T](NewSession(conn))
+}
+
+// 使用已有的session创建T类型的查询, 用于在事务中查询
+func QuerySession[T any](session *Session) (*TypedQuery[T], error) {
+	return newTypedQuery[T](session)
+}
+
+func newTypedQuery[T any](session *Session) (*TypedQuery[T], error) {
+	if reflect.TypeOf((*T)(nil)).Elem().Kind() != reflect.Struct {
+		return nil, define.UnsupportedTypeError
+	}
+	return &TypedQuery[T]{session: session}, nil
+}
+
+func (q *TypedQuery[T]) Table(tableName string) *TypedQuery[T] {
+	q.session.Table(tableName)
+	return q
+}
+
+func (q *TypedQuery[T]) Select(column ...string) *TypedQuery[T] {
+	q.session.Select(column...)
+	return q
+}
+
+func (q *TypedQuery[T]) Distinct() *TypedQuery[T] {
+	q.session.Distinct()
+	return q
+}
+
+func (q *TypedQuery[T]) Join(table, first, operator, second string) *TypedQuery[T] {
+	q.session.Join(table, first, operator, second)
+	return q
+}
+
+func (q *TypedQuery[T]) LeftJoin(table, first, operator, second string) *TypedQuery[T] {
+	q.session.LeftJoin(table, first, operator, second)
+	return q
+}
+
+func (q *TypedQuery[T]) Where(column, operator string, parameters interface{}) *TypedQuery[T] {
+	q.session.Where(column, operator, parameters)
+	return q
+}
+
+func (q *TypedQuery[T]) OrWhere(column, operator string, parameters interface{}) *TypedQuery[T] {
+	q.session.OrWhere(column, operator, parameters)
+	return q
+}
+
+func (q *TypedQuery[T]) WhereIn(column string, parameters ...interface{}) *TypedQuery[T] {
+	q.session.queryBuilder.WhereIn(column, parameters...)
+	return q
+}
+
+func (q *TypedQuery[T]) WhereNotIn(column string, parameters ...interface{}) *TypedQuery[T] {
+	q.session.queryBuilder.WhereNotIn(column, parameters...)
+	return q
+}
+
+func (q *TypedQuery[T]) WhereBetween(column string, first, last interface{}) *TypedQuery[T] {
+	q.session.WhereBetween(column, first, last)
+	return q
+}
+
+func (q *TypedQuery[T]) WhereNull(column string) *TypedQuery[T] {
+	q.session.WhereNull(column)
+	return q
+}
+
+func (q *TypedQuery[T]) WhereNotNull(column string) *TypedQuery[T] {
+	q.session.WhereNotNull(column)
+	return q
+}
+
+func (q *TypedQuery[T]) OrderBy(column string, direction string) *TypedQuery[T] {
+	q.session.OrderBy(column, direction)
+	return q
+}
+
+func (q *TypedQuery[T]) With(relations ...string) *TypedQuery[T] {
+	q.session.With(relations...)
+	return q
+}
+
+func (q *TypedQuery[T]) Skip(n int) *TypedQuery[T] {
+	q.session.Skip(n)
+	return q
+}
+
+func (q *TypedQuery[T]) Take(n int) *TypedQuery[T] {
+	q.session.Take(n)
+	return q
+}
+
+// 查询所有数据
+func (q *TypedQuery[T]) All(ctx context.Context, column ...string) ([]T, error) {
+	var results []T
+	defer q.withContext(ctx)()
+	if err := q.session.Get(&results, column...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// 查询第一条数据, 没有数据时返回ErrRecordNotFound
+func (q *TypedQuery[T]) First(ctx context.Context, column ...string) (T, error) {
+	var result T
+	defer q.withContext(ctx)()
+	err := q.session.First(&result, column...)
+	return result, err
+}
+
+// 根据主键查询
+func (q *TypedQuery[T]) Find(ctx context.Context, id interface{}, column ...string) (T, error) {
+	var result T
+	defer q.withContext(ctx)()
+	err := q.session.Find(id, &result, column...)
+	return result, err
+}
+
+// 创建T类型的游标
+func (q *TypedQuery[T]) Cursor(ctx context.Context, column ...string) (*TypedCursor[T], error) {
+	var object T
+	defer q.withContext(ctx)()
+	cursor, err := q.session.Cursor(&object, column...)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedCursor[T]{cursor: cursor}, nil
+}
+
+// 本次查询使用ctx, 返回的函数恢复session原来的ctx
+func (q *TypedQuery[T]) withContext(ctx context.Context) func() {
+	prev := q.session.ctx
+	q.session.ctx = ctx
+	return func() {
+		q.session.ctx = prev
+	}
+}
+
+// T类型的游标
+type TypedCursor[T any] struct {
+	cursor *Cursor
+}
+
+func (c *TypedCursor[T]) Next() bool {
+	return c.cursor.Next()
+}
+
+// 扫描当前行
+func (c *TypedCursor[T]) Scan() (T, error) {
+	var result T
+	err := c.cursor.Scan(&result)
+	return result, err
+}
+
+func (c *TypedCursor[T]) Err() error {
+	return c.cursor.Err()
+}
+
+func (c *TypedCursor[T]) Close() error {
+	return c.cursor.Close()
+}
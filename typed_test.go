@@ -0,0 +1,85 @@
+package sprydb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Soul-Mate/sprydb/define"
+)
+
+func TestQuery(t *testing.T) {
+	columns, rows := fakeUserRows()
+	conn, d := newFakeConnection(t, columns, rows)
+	ctx := context.Background()
+
+	q, err := Query[fakeUser](conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, err := q.WhereIn("id", 1, 2, 3).All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 3 || users[1].Name != "bar" {
+		t.Errorf("All error: %v", users)
+	}
+	if want := "select `id`,`name` from `users` where `id` in (?,?,?)"; d.queries[0] != want {
+		t.Errorf("All sql error: %s", d.queries[0])
+	}
+
+	user, err := q.First(ctx)
+	if err != nil || user.Id != 1 {
+		t.Errorf("First error: %v %v", user, err)
+	}
+
+	if user, err = q.Find(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	cursor, err := q.Cursor(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for cursor.Next() {
+		if user, err = cursor.Scan(); err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != 3 || user.Name != "baz" || cursor.Err() != nil {
+		t.Errorf("Cursor error: %d %v %v", n, user, cursor.Err())
+	}
+	assertNoConnectionInUse(t, conn, "Query")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err = q.All(canceled); !errors.Is(err, context.Canceled) {
+		t.Errorf("All should use the given context, got %v", err)
+	}
+}
+
+func TestQuery_NotFound(t *testing.T) {
+	conn, _ := newFakeConnection(t, []string{"id", "name"}, nil)
+	q, err := Query[fakeUser](conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = q.First(context.Background()); err != define.ErrRecordNotFound {
+		t.Errorf("First should return ErrRecordNotFound, got %v", err)
+	}
+}
+
+func TestQuery_UnsupportedType(t *testing.T) {
+	conn, d := newFakeConnection(t, []string{"id", "name"}, nil)
+	if q, err := Query[int](conn); q != nil || err != define.UnsupportedTypeError {
+		t.Errorf("Query should reject non struct type, got %v", err)
+	}
+	if _, err := QuerySession[*fakeUser](NewSession(conn)); err != define.UnsupportedTypeError {
+		t.Errorf("QuerySession should reject pointer type, got %v", err)
+	}
+	if len(d.queries) != 0 {
+		t.Errorf("unsupported type should not query: %v", d.queries)
+	}
+}
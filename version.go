@@ -0,0 +1,17 @@
+package sprydb
+
+import (
+	"github.com/Soul-Mate/sprydb/mapper"
+)
+
+// 更新的对象声明了version列时, 增加版本号相等的where条件
+// 返回的映射器用于更新成功后递增对象的版本号, 没有version列时返回nil
+func (s *Session) useVersion(objMapper *mapper.Mapper) *mapper.Mapper {
+	column := objMapper.GetVersionColumn()
+	if column == "" {
+		return nil
+	}
+	version, _ := objMapper.GetValueByColumn(column)
+	s.queryBuilder.Where(column, "=", version)
+	return objMapper
+}
@@ -0,0 +1,43 @@
+package sprydb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Soul-Mate/sprydb/define"
+)
+
+type versionConfig struct {
+	Id      int64  `spry:"col:id"`
+	Value   string `spry:"col:value"`
+	Version int    `spry:"col:version;version"`
+}
+
+func (versionConfig) Table() string { return "configs" }
+
+func TestSession_OptimisticLock(t *testing.T) {
+	conn, d := newFakeConnection(t, nil, nil)
+
+	config := &versionConfig{Id: 1, Value: "foo", Version: 3}
+	if err := conn.Save(config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Version != 4 {
+		t.Errorf("version not bumped: %d", config.Version)
+	}
+	if d.execs[0] != "update `configs` set `id` = ?,`value` = ?,`version` = `version` + 1 where `id` = ? and `version` = ?" {
+		t.Errorf("update sql error: %s", d.execs[0])
+	}
+	if args := d.args[0]; len(args) != 4 || args[2] != int64(1) || args[3] != int64(3) {
+		t.Errorf("update bindings error: %v", args)
+	}
+
+	d.zeroAffected = true
+	_, err := conn.Where("id", "=", 1).Update(config)
+	if !errors.Is(err, define.ErrStaleObject) {
+		t.Errorf("want ErrStaleObject, got %v", err)
+	}
+	if config.Version != 4 {
+		t.Errorf("version bumped on stale update: %d", config.Version)
+	}
+}